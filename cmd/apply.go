@@ -10,11 +10,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var applyConfigPath string
+var applyConfigPaths []string
 var applyEnvironments []string
 
 func init() {
-	applyCmd.PersistentFlags().StringVarP(&applyConfigPath, "config", "c", constants.ZeroProjectYml, "config path")
+	applyCmd.PersistentFlags().StringSliceVarP(&applyConfigPaths, "config", "c", []string{constants.ZeroProjectYml}, "config path - specify multiple times to layer config files, later files win")
 	applyCmd.PersistentFlags().StringSliceVarP(&applyEnvironments, "env", "e", []string{}, "environments to set up (staging, production) - specify multiple times for multiple")
 
 	rootCmd.AddCommand(applyCmd)
@@ -29,7 +29,7 @@ var applyCmd = &cobra.Command{
 			log.Println(err)
 			rootDir = projectconfig.RootDir
 		}
-		applyErr := apply.Apply(rootDir, applyConfigPath, applyEnvironments)
+		applyErr := apply.Apply(rootDir, applyConfigPaths, applyEnvironments)
 		if applyErr != nil {
 			log.Fatal(applyErr)
 		}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/util/exit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	viewConfigPaths []string
+	viewResolved    bool
+)
+
+func init() {
+	configViewCmd.Flags().StringSliceVarP(&viewConfigPaths, "config", "c", []string{constants.ZeroProjectYml}, "config path - specify multiple times to layer config files, later files win")
+	configViewCmd.Flags().BoolVarP(&viewResolved, "resolved", "r", false, "resolve secrets and references in parameter values")
+
+	configCmd.AddCommand(configViewCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the project configuration",
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: fmt.Sprintf("Print the project config merged from %s, %s and any --config files", constants.ZeroProjectYml, constants.ZeroProjectLocalYml),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePaths := []string{}
+		for _, configPath := range viewConfigPaths {
+			configFilePaths = append(configFilePaths, path.Join(projectconfig.RootDir, configPath))
+		}
+
+		config, sources, err := projectconfig.LoadLayeredConfig(viewResolved, configFilePaths...)
+		if err != nil {
+			exit.Fatal("%v", err)
+		}
+		content, err := projectconfig.RenderWithSources(config, sources)
+		if err != nil {
+			exit.Fatal("%v", err)
+		}
+		fmt.Print(content)
+	},
+}
@@ -5,6 +5,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/commitdev/zero/internal/config/moduleconfig"
 	"github.com/commitdev/zero/internal/config/projectconfig"
 	"github.com/commitdev/zero/internal/constants"
 	"github.com/commitdev/zero/internal/generate"
@@ -15,13 +16,15 @@ import (
 )
 
 var (
-	createConfigPath string
-	overwriteFiles   bool
+	createConfigPaths []string
+	overwriteFiles    bool
+	strictTemplates   bool
 )
 
 func init() {
-	createCmd.PersistentFlags().StringVarP(&createConfigPath, "config", "c", constants.ZeroProjectYml, "config path")
+	createCmd.PersistentFlags().StringSliceVarP(&createConfigPaths, "config", "c", []string{constants.ZeroProjectYml}, "config path - specify multiple times to layer config files, later files win")
 	createCmd.PersistentFlags().BoolVarP(&overwriteFiles, "overwrite", "o", false, "overwrite pre-existing files")
+	createCmd.PersistentFlags().BoolVar(&strictTemplates, "strict", false, "fail on templates referencing undefined keys, overrides the template.missingKey of modules")
 
 	rootCmd.AddCommand(createCmd)
 }
@@ -30,24 +33,36 @@ var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: fmt.Sprintf("Create projects for modules and configuration specified in %s", constants.ZeroProjectYml),
 	Run: func(cmd *cobra.Command, args []string) {
-		Create(projectconfig.RootDir, createConfigPath)
+		Create(projectconfig.RootDir, createConfigPaths)
 	},
 }
 
-func Create(dir string, createConfigPath string) {
-	if strings.Trim(createConfigPath, " ") == "" {
-		exit.Fatal("config path cannot be empty!")
+func Create(dir string, createConfigPaths []string) {
+	configFilePaths := []string{}
+	for _, createConfigPath := range createConfigPaths {
+		if strings.Trim(createConfigPath, " ") == "" {
+			exit.Fatal("config path cannot be empty!")
+		}
+		configFilePaths = append(configFilePaths, path.Join(dir, createConfigPath))
+	}
+	projectConfig, err := projectconfig.LoadConfig(configFilePaths...)
+	if err != nil {
+		exit.Fatal("%v", err)
 	}
-	configFilePath := path.Join(dir, createConfigPath)
-	projectConfig := projectconfig.LoadConfig(configFilePath)
 
-	generate.Generate(*projectConfig, overwriteFiles)
+	manifest, err := generate.Generate(*projectConfig, overwriteFiles, strictTemplates)
+	if err != nil {
+		exit.Fatal("%v", err)
+	}
+	if err := generate.WriteManifest(dir, manifest); err != nil {
+		exit.Fatal("Failed to write %s: %v", constants.ZeroManifestFile, err)
+	}
 
 	if projectConfig.ShouldPushRepositories {
 		flog.Infof(":up_arrow: Done Rendering - committing repositories to version control.")
 
 		for _, module := range projectConfig.Modules {
-			err, githubApiKey := projectconfig.ReadVendorCredentialsFromModule(module, "github")
+			err, githubApiKey := moduleconfig.ReadVendorCredentialsFromModule(module, "github")
 			if err != nil {
 				flog.Errorf(err.Error())
 			}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/util/exit"
+	"github.com/commitdev/zero/pkg/util/flog"
+	"github.com/spf13/cobra"
+)
+
+var migrateConfigPath string
+
+func init() {
+	migrateCmd.PersistentFlags().StringVarP(&migrateConfigPath, "config", "c", constants.ZeroProjectYml, "config path")
+
+	rootCmd.AddCommand(migrateCmd)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: fmt.Sprintf("Upgrade %s to the latest format supported by this version of Zero", constants.ZeroProjectYml),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePath := path.Join(projectconfig.RootDir, migrateConfigPath)
+
+		applied, backupPath, err := projectconfig.MigrateFile(configFilePath)
+		if err != nil {
+			exit.Fatal("Failed to migrate %s: %v", configFilePath, err)
+		}
+		if len(applied) == 0 {
+			flog.Infof(":check_mark_button: %s is already at apiVersion %s", configFilePath, projectconfig.CurrentAPIVersion)
+			return
+		}
+
+		for _, m := range applied {
+			flog.Infof("%s -> %s: %s", m.From, m.To, m.Description)
+		}
+		flog.Infof(":check_mark_button: Migrated %s to apiVersion %s. The original file was saved to %s, comments are not preserved so you may want to copy them over.", configFilePath, projectconfig.CurrentAPIVersion, backupPath)
+	},
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/internal/docs"
+	"github.com/commitdev/zero/internal/golden"
+	"github.com/commitdev/zero/internal/lint"
+	"github.com/commitdev/zero/internal/module"
+	"github.com/commitdev/zero/internal/scaffold"
+	"github.com/commitdev/zero/pkg/util/exit"
+	"github.com/commitdev/zero/pkg/util/flog"
+	"github.com/commitdev/zero/version"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateGoldenFiles bool
+	moduleDocsOutput  string
+)
+
+func init() {
+	moduleDocsCmd.Flags().StringVarP(&moduleDocsOutput, "output", "o", "", "write the Markdown to this file instead of stdout")
+	moduleTestCmd.Flags().BoolVarP(&updateGoldenFiles, "update", "u", false, "regenerate the expected output of the fixtures instead of comparing it")
+
+	moduleCmd.AddCommand(moduleInitCmd)
+	moduleCmd.AddCommand(moduleTestCmd)
+	moduleCmd.AddCommand(moduleDocsCmd)
+	moduleCmd.AddCommand(moduleLintCmd)
+	rootCmd.AddCommand(moduleCmd)
+}
+
+var moduleCmd = &cobra.Command{
+	Use:   "module",
+	Short: "Tools for module authors",
+}
+
+var moduleInitCmd = &cobra.Command{
+	Use:   "init [name]",
+	Short: "Create a skeleton module in a new directory",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info := scaffold.ModuleInfo{ZeroVersion: scaffold.ZeroVersionConstraint(version.AppVersion)}
+		if len(args) > 0 {
+			info.Name = args[0]
+		}
+
+		var err error
+		if info.Name, err = promptModuleInfo("Module name", info.Name, validateModuleName); err != nil {
+			exit.Fatal("Prompt failed %v", err)
+		}
+		if info.Description, err = promptModuleInfo("Description", "", nil); err != nil {
+			exit.Fatal("Prompt failed %v", err)
+		}
+		if info.Author, err = promptModuleInfo("Author", "", nil); err != nil {
+			exit.Fatal("Prompt failed %v", err)
+		}
+
+		dir := path.Join(projectconfig.RootDir, info.Name)
+		if err := scaffold.Scaffold(dir, info); err != nil {
+			exit.Fatal("Failed to create module: %v", err)
+		}
+		flog.Infof(`:tada: Done - Your module has been created in %s.
+Edit zero-module.yml to declare its parameters and add the files to template to templates/`, dir)
+	},
+}
+
+var moduleTestCmd = &cobra.Command{
+	Use:   "test [module directory]",
+	Short: fmt.Sprintf("Render the module with each fixture of %s/*%s and compare the output to %s/<fixture>/%s", golden.TestsDir, golden.FixtureExtension, golden.TestsDir, golden.ExpectedDir),
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		moduleDir := "."
+		if len(args) > 0 {
+			moduleDir = args[0]
+		}
+		moduleConfig, err := module.ParseModuleConfig(moduleDir)
+		if err != nil {
+			exit.Fatal("Unable to load module: %v", err)
+		}
+		fixtures, err := golden.LoadFixtures(moduleDir)
+		if err != nil {
+			exit.Fatal("Unable to load fixtures: %v", err)
+		}
+		if len(fixtures) == 0 {
+			exit.Fatal("No fixtures found in %s", path.Join(moduleDir, golden.TestsDir, "*"+golden.FixtureExtension))
+		}
+
+		failed := 0
+		for _, fixture := range fixtures {
+			result, err := golden.Run(moduleDir, moduleConfig, fixture, updateGoldenFiles)
+			if err != nil {
+				exit.Fatal("Failed to render: %v", err)
+			}
+			switch {
+			case result.Updated:
+				flog.Successf("%s: updated", fixture.Name)
+			case result.Passed():
+				flog.Successf("%s: passed", fixture.Name)
+			default:
+				failed++
+				flog.Errorf("%s: failed\n%s", fixture.Name, strings.Join(result.Differences, "\n"))
+			}
+		}
+		if failed > 0 {
+			exit.Error("%d of %d fixtures failed, run with --update to accept the changes", failed, len(fixtures))
+		}
+	},
+}
+
+var moduleDocsCmd = &cobra.Command{
+	Use:   "docs [module directory]",
+	Short: fmt.Sprintf("Print the Markdown reference of the module generated from its %s", constants.ZeroModuleYml),
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		moduleDir := "."
+		if len(args) > 0 {
+			moduleDir = args[0]
+		}
+		moduleConfig, err := module.ParseModuleConfig(moduleDir)
+		if err != nil {
+			exit.Fatal("Unable to load module: %v", err)
+		}
+		content, err := docs.RenderModule(moduleConfig)
+		if err != nil {
+			exit.Fatal("Failed to generate docs: %v", err)
+		}
+		if moduleDocsOutput == "" {
+			fmt.Println(content)
+			return
+		}
+		if err := ioutil.WriteFile(moduleDocsOutput, []byte(content+"\n"), 0644); err != nil {
+			exit.Fatal("Failed to write docs: %v", err)
+		}
+		flog.Infof(":memo: Docs written to %s", moduleDocsOutput)
+	},
+}
+
+var moduleLintCmd = &cobra.Command{
+	Use:   "lint [module directory]",
+	Short: fmt.Sprintf("Check the module's templates for undeclared parameters and functions, and %s for unused parameters", constants.ZeroModuleYml),
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		moduleDir := "."
+		if len(args) > 0 {
+			moduleDir = args[0]
+		}
+		moduleConfig, err := module.ParseModuleConfig(moduleDir)
+		if err != nil {
+			exit.Fatal("Unable to load module: %v", err)
+		}
+		issues, err := lint.Module(moduleDir, moduleConfig)
+		if err != nil {
+			exit.Fatal("Unable to lint module: %v", err)
+		}
+		errorCount := 0
+		for _, issue := range issues {
+			fmt.Println(issue)
+			if issue.Severity == lint.SeverityError {
+				errorCount++
+			}
+		}
+		// warnings don't fail the lint
+		if errorCount > 0 {
+			exit.Error("%d issue(s) found in %s", errorCount, moduleConfig.Name)
+		}
+		flog.Successf("No issues found in %s", moduleConfig.Name)
+	},
+}
+
+func promptModuleInfo(label string, defaultValue string, validate promptui.ValidateFunc) (string, error) {
+	if validate == nil {
+		validate = func(input string) error {
+			if input == "" {
+				return fmt.Errorf("%s is required", label)
+			}
+			return nil
+		}
+	}
+	prompt := promptui.Prompt{
+		Label:     label,
+		Default:   defaultValue,
+		AllowEdit: true,
+		Validate:  validate,
+	}
+	return prompt.Run()
+}
+
+func validateModuleName(input string) error {
+	if !regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`).MatchString(input) {
+		return errors.New("Invalid, Module Name: (can only contain alphanumeric chars, '-' and '_')")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/config/schema"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/util/exit"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+var schemas = map[string]func() *schema.Schema{
+	"module":  moduleconfig.Schema,
+	"project": projectconfig.Schema,
+}
+
+var schemaCmd = &cobra.Command{
+	Use:       "schema module|project",
+	Short:     fmt.Sprintf("Print the JSON Schema of %s or %s", constants.ZeroModuleYml, constants.ZeroProjectYml),
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"module", "project"},
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := json.MarshalIndent(schemas[args[0]](), "", "  ")
+		if err != nil {
+			exit.Fatal("Failed to generate schema: %v", err)
+		}
+		fmt.Println(string(content))
+	},
+}
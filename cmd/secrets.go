@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/secrets"
+	"github.com/commitdev/zero/pkg/util/exit"
+	"github.com/commitdev/zero/pkg/util/flog"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+const secretsFileTemplate = `# Secret module parameters, merged into the module parameters of zero-project.yml
+modules: {}
+`
+
+var (
+	secretsConfigPath  string
+	rotateToPassphrase bool
+)
+
+func init() {
+	secretsCmd.PersistentFlags().StringVarP(&secretsConfigPath, "config", "c", constants.ZeroProjectYml, "config path")
+	secretsRotateCmd.Flags().BoolVarP(&rotateToPassphrase, "passphrase", "p", false, "encrypt with a passphrase instead of a key stored in ~/.zero")
+
+	secretsCmd.AddCommand(secretsEditCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: fmt.Sprintf("Manage the encrypted secrets of the project stored in %s", constants.ZeroSecretsFile),
+}
+
+var secretsEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Decrypt the secrets file, open it in $EDITOR and encrypt it again",
+	Run: func(cmd *cobra.Command, args []string) {
+		secretsPath := projectconfig.SecretsFilePath(path.Join(projectconfig.RootDir, secretsConfigPath))
+		err := secrets.Edit(secretsPath, []byte(secretsFileTemplate), func(content []byte) error {
+			_, parseErr := projectconfig.ParseSecretValues(content)
+			return parseErr
+		})
+		if err != nil {
+			exit.Fatal("Failed to edit secrets: %v", err)
+		}
+		flog.Infof(":lock: Secrets saved to %s", secretsPath)
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Encrypt the secrets file with a new key",
+	Run: func(cmd *cobra.Command, args []string) {
+		secretsPath := projectconfig.SecretsFilePath(path.Join(projectconfig.RootDir, secretsConfigPath))
+
+		var key secrets.Key
+		var err error
+		if rotateToPassphrase {
+			prompt := promptui.Prompt{
+				Label: "New passphrase",
+				Mask:  '*',
+			}
+			passphrase, promptErr := prompt.Run()
+			if promptErr != nil {
+				exit.Fatal("Prompt failed %v", promptErr)
+			}
+			key, err = secrets.NewPassphraseKey(passphrase)
+		} else {
+			key, err = secrets.NewKeyFileKey()
+		}
+		if err != nil {
+			exit.Fatal("Failed to create a new key: %v", err)
+		}
+
+		if err := secrets.Rotate(secretsPath, key); err != nil {
+			exit.Fatal("Failed to rotate secrets: %v", err)
+		}
+		flog.Infof(":key: %s is now encrypted with %s", secretsPath, key.Description())
+	},
+}
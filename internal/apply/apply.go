@@ -21,13 +21,19 @@ import (
 	"github.com/manifoldco/promptui"
 )
 
-func Apply(rootDir string, configPath string, environments []string) error {
+func Apply(rootDir string, configPaths []string, environments []string) error {
 	var errs []error
-	if strings.Trim(configPath, " ") == "" {
-		exit.Fatal("config path cannot be empty!")
+	configFilePaths := []string{}
+	for _, configPath := range configPaths {
+		if strings.Trim(configPath, " ") == "" {
+			exit.Fatal("config path cannot be empty!")
+		}
+		configFilePaths = append(configFilePaths, path.Join(rootDir, configPath))
+	}
+	projectConfig, err := projectconfig.LoadConfig(configFilePaths...)
+	if err != nil {
+		return err
 	}
-	configFilePath := path.Join(rootDir, configPath)
-	projectConfig := projectconfig.LoadConfig(configFilePath)
 
 	if len(environments) == 0 {
 		fmt.Println(`Choose the environments to apply. This will create infrastructure, CI pipelines, etc.
@@ -107,8 +113,20 @@ func modulesWalkCmd(lifecycleName string, dir string, projectConfig *projectconf
 			exit.Fatal("Failed to load Module: %s", err)
 		}
 
+		// The values may have been edited in zero-project.yml since they were prompted
+		if lifecycleName == "check" {
+			if err := modConfig.ValidateParameters(mod.Parameters); err != nil {
+				moduleErrors = append(moduleErrors, err)
+				return nil
+			}
+			if err := modConfig.ValidateCredentials(mod.Parameters); err != nil {
+				moduleErrors = append(moduleErrors, fmt.Errorf("Module (%s) %v", modConfig.Name, err))
+				return nil
+			}
+		}
+
 		envVarTranslationMap := modConfig.GetParamEnvVarTranslationMap()
-		envList = util.AppendProjectEnvToCmdEnv(mod.Parameters, envList, envVarTranslationMap)
+		envList = util.AppendProjectEnvToCmdEnv(mod.Parameters.Strings(), envList, envVarTranslationMap)
 		flog.Debugf("Env injected: %#v", envList)
 
 		// only print msg for apply, or else it gets a little spammy
@@ -135,9 +153,9 @@ func modulesWalkCmd(lifecycleName string, dir string, projectConfig *projectconf
 }
 
 func getModuleOperationCommand(mod moduleconfig.ModuleConfig, operation string) (operationCommand []string) {
-	defaultCheck := []string{"make", "check"}
-	defaultApply := []string{"make"}
-	defaultSummary := []string{"make", "summary"}
+	defaultCheck := strings.Fields(moduleconfig.DefaultCommands.Check)
+	defaultApply := strings.Fields(moduleconfig.DefaultCommands.Apply)
+	defaultSummary := strings.Fields(moduleconfig.DefaultCommands.Summary)
 
 	switch operation {
 	case "check":
@@ -1,14 +1,36 @@
-// This module is invoked when we do template rendering during "zero create."
+// This module is invoked when we do template rendering during "zero create,"
+// the conditions are applied to the list of files of the module before anything is rendered.
 //
 // Each module can have a "conditions" section in their zero-module.yml that
 // specifies a condition in the form:
 //
-//   conditions:
-//     - action: ignoreFile
-//       matchField: <the name of a parameter in zero-module.yml>
-//       whenValue: <value for the matchField that triggers this condition>
-//       data:
-//       - <arbitrary string>
+//	conditions:
+//	  - action: ignoreFile
+//	    matchField: <the name of a parameter in zero-module.yml>
+//	    whenValue: <value for the matchField that triggers this condition>
+//	    data:
+//	    - <path or glob pattern, relative to the module's output directory>
+//
+// The value can also be tested with an operator (equals, notEquals, in, notIn,
+// matches, exists, empty), or conditions can be combined with all / any / not:
+//
+//	conditions:
+//	  - action: ignoreFile
+//	    any:
+//	      - matchField: database
+//	        operator: in
+//	        values: [none, sqlite]
+//	      - not:
+//	          matchField: backend
+//	          operator: exists
+//	    data:
+//	    - <arbitrary string>
+//
+// The same evaluator (see Evaluate) is used for the prompt conditions of "zero init".
+//
+// The actions ignoreFile, includeOnly and renameFile are applied to the list
+// of files before rendering (see ApplyToFiles), appendToFile and replaceInFile
+// to the rendered files (see ApplyToOutput).
 //
 // The structure for this is defined in:
 // internal/config/projectconfig/project_config.go.
@@ -17,39 +39,193 @@
 //
 // See: internal/generate/generate_modules.go
 // See: internal/config/projectconfig/project_config.go
-//
 package condition
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/pkg/util/flog"
 )
 
-// Function dispatch for any kind of condition.
-func Perform(cond projectconfig.Condition, mod projectconfig.Module) {
-	value, found := mod.Parameters[cond.MatchField]
+// File is a file of a module, Source is its path relative to the module's template directory
+// and Destination its path relative to the output directory, both slash separated
+type File struct {
+	Source      string
+	Destination string
+}
+
+// ApplyToFiles applies the conditions met by the module's parameters to the list of the module's files,
+// before they are rendered, and returns the files to render. The files affected by each condition are reported.
+// Invalid conditions are an error, see projectconfig.Condition.Validate
+func ApplyToFiles(conditions []projectconfig.Condition, mod projectconfig.Module, files []File) ([]File, error) {
+	values := mod.Parameters.Strings()
+	for i, cond := range conditions {
+		if err := cond.Validate(); err != nil {
+			return nil, fmt.Errorf("conditions[%d]: %v", i, err)
+		}
+		// Skip if the condition isn't met.
+		if !Evaluate(cond.Expression, values) {
+			flog.Debugf("Condition %s (%s) not met", cond.Action, cond.Expression)
+			continue
+		}
 
-	// Exit if the condition isn't met.
-	if !found || value != cond.WhenValue {
-		return
+		// Okay, the condition was met, let's execute it.
+		var affected []string
+		switch cond.Action {
+		// paths are relative to the module's output directory, as they were when ignored files were removed after rendering
+		case projectconfig.ActionIgnoreFile:
+			files, affected = filterFiles(files, func(f File) bool { return !matchAny(cond.Data, f.Destination) })
+		case projectconfig.ActionIncludeOnly:
+			files, affected = filterFiles(files, func(f File) bool { return matchAny(cond.Data, f.Destination) })
+		case projectconfig.ActionRenameFile:
+			affected = renameFile(cond.Data[0], cond.Data[1], files)
+		}
+		if len(affected) > 0 {
+			flog.Infof("%s (%s): %s", cond.Action, cond.Expression, strings.Join(affected, ", "))
+		}
 	}
+	warnDuplicateDestinations(files)
+	return files, nil
+}
 
-	// Okay, the condition was met, let's execute it.
-	switch cond.Action {
-	case "ignoreFile":
-		ignoreFile(cond.Data, mod)
+// ApplyToOutput applies the conditions met by the module's parameters to the rendered files,
+// rendered are the destinations of the files written by this run so files kept from a previous run are left untouched
+func ApplyToOutput(conditions []projectconfig.Condition, mod projectconfig.Module, outputDir string, rendered []string) error {
+	values := mod.Parameters.Strings()
+	for i, cond := range conditions {
+		if cond.Action != projectconfig.ActionAppendToFile && cond.Action != projectconfig.ActionReplaceInFile {
+			continue
+		}
+		if err := cond.Validate(); err != nil {
+			return fmt.Errorf("conditions[%d]: %v", i, err)
+		}
+		if !Evaluate(cond.Expression, values) {
+			continue
+		}
+
+		target := path.Clean(cond.Data[0])
+		found := false
+		for _, destination := range rendered {
+			found = found || destination == target
+		}
+		if !found {
+			flog.Warnf("%s (%s): %s was not rendered, skipping", cond.Action, cond.Expression, target)
+			continue
+		}
+
+		filePath := filepath.Join(outputDir, filepath.FromSlash(target))
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		switch cond.Action {
+		case projectconfig.ActionAppendToFile:
+			content = appendToFile(content, cond.Data[1:])
+		case projectconfig.ActionReplaceInFile:
+			content = []byte(strings.ReplaceAll(string(content), cond.Data[1], cond.Data[2]))
+		}
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to apply %s to %s: %v", cond.Action, target, err)
+		}
+		flog.Infof("%s (%s): %s", cond.Action, cond.Expression, target)
 	}
+	return nil
 }
 
-// Excludes paths from template rendering.
-// This occurs after-the-fact. That is, we render all templates to disk, then
-// use 'paths' to determine which files and directories to remove from disk.
-//
-func ignoreFile(paths []string, mod projectconfig.Module) {
-	for _, file := range paths {
-		filepath := path.Join(mod.Files.Directory, file)
-		os.RemoveAll(filepath)
+// filterFiles keeps the files for which keep returns true, and returns the sources of the others
+func filterFiles(files []File, keep func(File) bool) ([]File, []string) {
+	kept := []File{}
+	skipped := []string{}
+	for _, f := range files {
+		if keep(f) {
+			kept = append(kept, f)
+		} else {
+			skipped = append(skipped, f.Source)
+		}
+	}
+	return kept, skipped
+}
+
+// renameFile changes the destination of the file, or the files of the directory, from
+func renameFile(from string, to string, files []File) []string {
+	from = path.Clean(from)
+	to = path.Clean(to)
+	renamed := []string{}
+	for i, f := range files {
+		switch {
+		case f.Source == from:
+			files[i].Destination = to
+		case strings.HasPrefix(f.Source, from+"/"):
+			files[i].Destination = path.Join(to, strings.TrimPrefix(f.Source, from+"/"))
+		default:
+			continue
+		}
+		renamed = append(renamed, fmt.Sprintf("%s -> %s", f.Source, files[i].Destination))
+	}
+	return renamed
+}
+
+func appendToFile(content []byte, lines []string) []byte {
+	text := string(content)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return []byte(text + strings.Join(lines, "\n") + "\n")
+}
+
+func warnDuplicateDestinations(files []File) {
+	sources := map[string]string{}
+	for _, f := range files {
+		if other, ok := sources[f.Destination]; ok {
+			flog.Warnf("%s and %s are both rendered as %s, use ignoreFile to pick one", other, f.Source, f.Destination)
+		}
+		sources[f.Destination] = f.Source
+	}
+}
+
+func matchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether a slash separated path matches a pattern.
+// Each segment of the pattern is matched with path.Match, `**` matches any number of segments,
+// and a pattern matching a directory matches all the files under it.
+func MatchPath(pattern string, filePath string) bool {
+	patternSegments := strings.Split(strings.Trim(path.Clean("/"+pattern), "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path.Clean("/"+filePath), "/"), "/")
+	return matchSegments(patternSegments, pathSegments)
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
 	}
+	matched, err := path.Match(pattern[0], segments[0])
+	return err == nil && matched && matchSegments(pattern[1:], segments[1:])
 }
@@ -1,74 +1,183 @@
 package condition_test
 
 import (
-	"encoding/base64"
-	"math/rand"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/commitdev/zero/internal/condition"
 	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/stretchr/testify/assert"
 )
 
-func testSetup(paramKey, paramValue string) (string, projectconfig.Module) {
-	bytes := make([]byte, 15)
-	_, _ = rand.Read(bytes)
-	name := string(base64.StdEncoding.EncodeToString(bytes[:]))
+var modulePaths = []string{
+	"README.md",
+	"docs/setup.md",
+	"docs/img/logo.png",
+	"src/auth/login.go",
+	"src/main.go",
+}
 
-	_, _ = os.Create(name)
+func moduleFiles() []condition.File {
+	files := []condition.File{}
+	for _, p := range modulePaths {
+		files = append(files, condition.File{Source: p, Destination: p})
+	}
+	return files
+}
 
-	params := make(projectconfig.Parameters)
-	params[paramKey] = paramValue
+func sources(files []condition.File) []string {
+	result := []string{}
+	for _, f := range files {
+		result = append(result, f.Source)
+	}
+	return result
+}
 
-	mod := projectconfig.Module{
-		Parameters: params,
+func testModule(paramKey, paramValue string) projectconfig.Module {
+	return projectconfig.Module{
+		Parameters: projectconfig.Parameters{paramKey: paramValue},
 		Files: projectconfig.Files{
 			Directory: ".",
 			Source:    ".",
 		},
 	}
+}
 
-	return name, mod
+func fileCondition(action string, field string, value string, data ...string) projectconfig.Condition {
+	return projectconfig.Condition{
+		Action:     action,
+		Expression: projectconfig.Expression{MatchField: field, WhenValue: value},
+		Data:       data,
+	}
 }
 
-func TestPerformIgnoreFileConditionNotMet(t *testing.T) {
-	field := "testField"
-	value := "trigger"
+func ignoreFileCondition(field string, value string, patterns ...string) projectconfig.Condition {
+	return fileCondition(projectconfig.ActionIgnoreFile, field, value, patterns...)
+}
 
-	filename, mod := testSetup(field, "other value")
-	defer os.Remove(filename)
+// applyToFiles is condition.ApplyToFiles on the files of the test module, the conditions must be valid
+func applyToFiles(t *testing.T, conditions []projectconfig.Condition, mod projectconfig.Module) []condition.File {
+	files, err := condition.ApplyToFiles(conditions, mod, moduleFiles())
+	assert.NoError(t, err)
+	return files
+}
+
+func TestApplyToFilesIgnoreFileConditionNotMet(t *testing.T) {
+	mod := testModule("userAuth", "yes")
+	conditions := []projectconfig.Condition{ignoreFileCondition("userAuth", "no", "src/auth")}
+
+	assert.Equal(t, moduleFiles(), applyToFiles(t, conditions, mod))
+}
 
-	cond := projectconfig.Condition{
-		Action:     "ignoreFile",
-		MatchField: field,
-		WhenValue:  value,
-		Data:       []string{filename},
+func TestApplyToFilesIgnoreFileConditionMet(t *testing.T) {
+	mod := testModule("userAuth", "no")
+	conditions := []projectconfig.Condition{
+		ignoreFileCondition("userAuth", "no", "src/auth", "README.md"),
+		ignoreFileCondition("userAuth", "no", "**/*.png"),
 	}
-	condition.Perform(cond, mod)
 
-	_, err := os.Stat(filename)
-	if err != nil && !os.IsExist(err) {
-		t.Errorf("Expected %v not to be removed\n", filename)
+	assert.Equal(t, []string{"docs/setup.md", "src/main.go"}, sources(applyToFiles(t, conditions, mod)))
+}
+
+func TestApplyToFilesIncludeOnly(t *testing.T) {
+	mod := testModule("docsOnly", "yes")
+	conditions := []projectconfig.Condition{fileCondition(projectconfig.ActionIncludeOnly, "docsOnly", "yes", "docs", "README.md")}
+
+	assert.Equal(t, []string{"README.md", "docs/setup.md", "docs/img/logo.png"}, sources(applyToFiles(t, conditions, mod)))
+}
+
+func TestApplyToFilesRenameFile(t *testing.T) {
+	mod := testModule("language", "go")
+	conditions := []projectconfig.Condition{
+		fileCondition(projectconfig.ActionRenameFile, "language", "go", "src/main.go", "main.go"),
+		fileCondition(projectconfig.ActionRenameFile, "language", "go", "docs", "documentation"),
+		fileCondition(projectconfig.ActionRenameFile, "language", "node", "README.md", "README.node.md"),
 	}
+
+	files := applyToFiles(t, conditions, mod)
+	assert.Equal(t, []condition.File{
+		{Source: "README.md", Destination: "README.md"},
+		{Source: "docs/setup.md", Destination: "documentation/setup.md"},
+		{Source: "docs/img/logo.png", Destination: "documentation/img/logo.png"},
+		{Source: "src/auth/login.go", Destination: "src/auth/login.go"},
+		{Source: "src/main.go", Destination: "main.go"},
+	}, files)
 }
 
-func TestPerformIgnoreFileConditionMet(t *testing.T) {
-	field := "testField"
-	value := "trigger"
+func TestApplyToFilesIgnoreFileMatchesDestination(t *testing.T) {
+	mod := testModule("language", "go")
+	conditions := []projectconfig.Condition{
+		fileCondition(projectconfig.ActionRenameFile, "language", "go", "docs", "documentation"),
+		ignoreFileCondition("language", "go", "documentation/img"),
+	}
+
+	files := applyToFiles(t, conditions, mod)
+	assert.Equal(t, []string{"README.md", "docs/setup.md", "src/auth/login.go", "src/main.go"}, sources(files))
+}
 
-	filename, mod := testSetup(field, value)
-	defer os.Remove(filename)
+func TestApplyToOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "condition")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".env"), []byte("PORT=80"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kept.txt"), []byte("from a previous run"), 0644))
 
-	cond := projectconfig.Condition{
-		Action:     "ignoreFile",
-		MatchField: field,
-		WhenValue:  value,
-		Data:       []string{filename},
+	mod := testModule("database", "postgres")
+	conditions := []projectconfig.Condition{
+		fileCondition(projectconfig.ActionAppendToFile, "database", "postgres", ".env", "DB_HOST=localhost", "DB_PORT=5432"),
+		fileCondition(projectconfig.ActionReplaceInFile, "database", "postgres", ".env", "PORT=80", "PORT=8080"),
+		fileCondition(projectconfig.ActionReplaceInFile, "database", "mysql", ".env", "DB_PORT=5432", "DB_PORT=3306"),
+		fileCondition(projectconfig.ActionAppendToFile, "database", "postgres", "kept.txt", "appended"),
 	}
-	condition.Perform(cond, mod)
+	assert.NoError(t, condition.ApplyToOutput(conditions, mod, dir, []string{".env"}))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, ".env"))
+	assert.NoError(t, err)
+	assert.Equal(t, "PORT=8080\nDB_HOST=localhost\nDB_PORT=5432\n", string(content))
+
+	info, err := os.Stat(filepath.Join(dir, ".env"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the file mode should be preserved")
 
-	_, err := os.Stat(filename)
-	if !os.IsNotExist(err) {
-		t.Errorf("Expected %v to be removed\n", filename)
+	content, err = ioutil.ReadFile(filepath.Join(dir, "kept.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "from a previous run", string(content), "files which were not rendered should be left untouched")
+}
+
+func TestApplyInvalidConditions(t *testing.T) {
+	mod := testModule("language", "go")
+
+	_, err := condition.ApplyToFiles([]projectconfig.Condition{fileCondition(projectconfig.ActionRenameFile, "language", "go", "x")}, mod, moduleFiles())
+	assert.EqualError(t, err, "conditions[0]: renameFile requires data: [<path>, <new path>]")
+	_, err = condition.ApplyToFiles([]projectconfig.Condition{fileCondition("deleteFile", "language", "go", "x")}, mod, moduleFiles())
+	assert.Error(t, err, "unknown actions should not be ignored")
+
+	assert.EqualError(t, condition.ApplyToOutput([]projectconfig.Condition{fileCondition(projectconfig.ActionReplaceInFile, "language", "go", ".env")}, mod, ".", []string{".env"}),
+		"conditions[0]: replaceInFile requires data: [<path>, <old>, <new>]")
+}
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"README.md", "README.md", true},
+		{"docs", "docs/img/logo.png", true},
+		{"docs/", "docs/setup.md", true},
+		{"doc", "docs/setup.md", false},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/setup.md", false},
+		{"**/*.md", "docs/setup.md", true},
+		{"**/*.md", "README.md", true},
+		{"docs/**/*.png", "docs/img/logo.png", true},
+		{"src/*/login.go", "src/auth/login.go", true},
+		{"src/*.go", "src/auth/login.go", false},
+		{"./src/main.go", "src/main.go", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, condition.MatchPath(c.pattern, c.path), "%s should match %s: %v", c.pattern, c.path, c.expected)
 	}
 }
@@ -0,0 +1,51 @@
+package condition
+
+import (
+	"regexp"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/util"
+)
+
+// Evaluate returns whether the expression is satisfied by values, the parameters in their string form.
+// It is shared by the prompt conditions of `zero init` and the file conditions of `zero create`.
+func Evaluate(expression projectconfig.Expression, values map[string]string) bool {
+	switch {
+	case expression.All != nil:
+		for _, e := range expression.All {
+			if !Evaluate(e, values) {
+				return false
+			}
+		}
+		return true
+	case expression.Any != nil:
+		for _, e := range expression.Any {
+			if Evaluate(e, values) {
+				return true
+			}
+		}
+		return false
+	case expression.Not != nil:
+		return !Evaluate(*expression.Not, values)
+	}
+
+	value, found := values[expression.MatchField]
+	switch expression.GetOperator() {
+	case projectconfig.OperatorEquals:
+		return found && value == expression.WhenValue
+	case projectconfig.OperatorNotEquals:
+		return !found || value != expression.WhenValue
+	case projectconfig.OperatorIn:
+		return found && util.ItemInSlice(expression.Values, value)
+	case projectconfig.OperatorNotIn:
+		return !found || !util.ItemInSlice(expression.Values, value)
+	case projectconfig.OperatorMatches:
+		pattern, err := regexp.Compile(expression.WhenValue)
+		return found && err == nil && pattern.MatchString(value)
+	case projectconfig.OperatorExists:
+		return found
+	case projectconfig.OperatorEmpty:
+		return !found || value == "" || value == "[]" || value == "{}"
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package condition_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/condition"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]string{
+		"region":     "us-west-2",
+		"database":   "postgres",
+		"empty":      "",
+		"subdomains": "[]",
+	}
+	field := func(name string, operator string, whenValue string, options ...string) projectconfig.Expression {
+		return projectconfig.Expression{MatchField: name, Operator: operator, WhenValue: whenValue, Values: options}
+	}
+
+	cases := []struct {
+		name       string
+		expression projectconfig.Expression
+		expected   bool
+	}{
+		{"equals by default", field("region", "", "us-west-2"), true},
+		{"equals", field("region", projectconfig.OperatorEquals, "us-east-1"), false},
+		{"equals missing field", field("missing", projectconfig.OperatorEquals, ""), false},
+		{"notEquals", field("region", projectconfig.OperatorNotEquals, "us-east-1"), true},
+		{"notEquals missing field", field("missing", projectconfig.OperatorNotEquals, "foo"), true},
+		{"in", field("database", projectconfig.OperatorIn, "", "mysql", "postgres"), true},
+		{"notIn", field("database", projectconfig.OperatorNotIn, "", "mysql", "postgres"), false},
+		{"matches", field("region", projectconfig.OperatorMatches, "^us-"), true},
+		{"matches missing field", field("missing", projectconfig.OperatorMatches, ".*"), false},
+		{"exists", field("empty", projectconfig.OperatorExists, ""), true},
+		{"exists missing field", field("missing", projectconfig.OperatorExists, ""), false},
+		{"empty", field("empty", projectconfig.OperatorEmpty, ""), true},
+		{"empty list", field("subdomains", projectconfig.OperatorEmpty, ""), true},
+		{"empty missing field", field("missing", projectconfig.OperatorEmpty, ""), true},
+		{"not empty", field("region", projectconfig.OperatorEmpty, ""), false},
+		{"all", projectconfig.Expression{All: []projectconfig.Expression{
+			field("region", "", "us-west-2"),
+			field("database", "", "mysql"),
+		}}, false},
+		{"any", projectconfig.Expression{Any: []projectconfig.Expression{
+			field("region", "", "us-west-2"),
+			field("database", "", "mysql"),
+		}}, true},
+		{"not", projectconfig.Expression{Not: &projectconfig.Expression{
+			All: []projectconfig.Expression{field("region", projectconfig.OperatorExists, "")},
+		}}, false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, condition.Evaluate(c.expression, values), "%s: %s", c.name, c.expression)
+	}
+}
@@ -0,0 +1,155 @@
+// Package migration upgrades raw config documents between versions of their format.
+//
+// Each config package (project, module) declares a Registry holding the version
+// of the format it currently supports and the ordered list of migrations
+// to get there. Documents are upgraded in memory every time they are loaded,
+// `zero migrate` writes the upgraded document back to disk.
+package migration
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/commitdev/zero/internal/constants"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// VersionKey is the key holding the format version of a config document
+const VersionKey = "apiVersion"
+
+// LegacyVersion is the version of documents which don't declare an apiVersion
+const LegacyVersion = "v0"
+
+// Migration upgrades a document from one version of the format to the next
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	// Migrate makes the changes to the document, the version is updated by the registry
+	Migrate func(doc *yaml.MapSlice) error
+}
+
+// Registry is the set of migrations for one kind of config file
+type Registry struct {
+	Kind       string
+	Current    string
+	Migrations []Migration
+}
+
+// Upgrade migrates the document to the current version and returns the migrations that were applied.
+// Documents newer than the current version are rejected.
+func (r Registry) Upgrade(doc *yaml.MapSlice) ([]Migration, error) {
+	version := LegacyVersion
+	if value, ok := Get(*doc, VersionKey); ok {
+		version = fmt.Sprintf("%v", value)
+	}
+
+	docVersion, err := parseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %s %q: %v", r.Kind, VersionKey, version, err)
+	}
+	currentVersion, err := parseVersion(r.Current)
+	if err != nil {
+		return nil, err
+	}
+	if docVersion > currentVersion {
+		return nil, fmt.Errorf("%s file uses %s %s but this version of Zero only supports up to %s, please upgrade Zero: %s",
+			r.Kind, VersionKey, version, r.Current, constants.ZeroReleaseURL)
+	}
+
+	applied := []Migration{}
+	for version != r.Current {
+		m, ok := r.from(version)
+		if !ok {
+			return applied, fmt.Errorf("no migration available for %s files from %s %s", r.Kind, VersionKey, version)
+		}
+		if m.Migrate != nil {
+			if err := m.Migrate(doc); err != nil {
+				return applied, fmt.Errorf("migrating %s file from %s to %s: %v", r.Kind, m.From, m.To, err)
+			}
+		}
+		Set(doc, VersionKey, m.To)
+		applied = append(applied, m)
+		version = m.To
+	}
+	return applied, nil
+}
+
+// UpgradeBytes is Upgrade for the yaml content of a file, returning the upgraded content
+func (r Registry) UpgradeBytes(data []byte) ([]byte, []Migration, error) {
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	applied, err := r.Upgrade(&doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(applied) == 0 {
+		return data, applied, nil
+	}
+	upgraded, err := yaml.Marshal(doc)
+	return upgraded, applied, err
+}
+
+// MigrateFile upgrades the file at filePath in place, keeping a copy of the original at filePath.bak.
+// The backup path is empty if the file was already up to date.
+func (r Registry) MigrateFile(filePath string) ([]Migration, string, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	upgraded, applied, err := r.UpgradeBytes(data)
+	if err != nil || len(applied) == 0 {
+		return applied, "", err
+	}
+
+	backupPath := filePath + ".bak"
+	if err := ioutil.WriteFile(backupPath, data, 0644); err != nil {
+		return nil, "", err
+	}
+	if err := ioutil.WriteFile(filePath, upgraded, 0644); err != nil {
+		return nil, backupPath, err
+	}
+	return applied, backupPath, nil
+}
+
+func (r Registry) from(version string) (Migration, bool) {
+	for _, m := range r.Migrations {
+		if m.From == version {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Get returns the value of key at the top level of doc
+func Get(doc yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range doc {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set sets the value of key at the top level of doc, new keys are added first
+func Set(doc *yaml.MapSlice, key string, value interface{}) {
+	for i, item := range *doc {
+		if item.Key == key {
+			(*doc)[i].Value = value
+			return
+		}
+	}
+	*doc = append(yaml.MapSlice{{Key: key, Value: value}}, *doc...)
+}
+
+// parseVersion parses versions in the form v1, v2...
+func parseVersion(version string) (int, error) {
+	if !strings.HasPrefix(version, "v") {
+		return 0, fmt.Errorf("expected a version like v1")
+	}
+	return strconv.Atoi(strings.TrimPrefix(version, "v"))
+}
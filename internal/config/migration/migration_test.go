@@ -0,0 +1,97 @@
+package migration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/migration"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testRegistry() migration.Registry {
+	return migration.Registry{
+		Kind:    "test",
+		Current: "v2",
+		Migrations: []migration.Migration{
+			{From: migration.LegacyVersion, To: "v1", Description: "Add apiVersion"},
+			{
+				From:        "v1",
+				To:          "v2",
+				Description: "Rename title to name",
+				Migrate: func(doc *yaml.MapSlice) error {
+					for i, item := range *doc {
+						if item.Key == "title" {
+							(*doc)[i].Key = "name"
+						}
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Run("Should apply all migrations to legacy documents", func(t *testing.T) {
+		upgraded, applied, err := testRegistry().UpgradeBytes([]byte("title: foo\nother: bar\n"))
+		assert.NoError(t, err)
+		assert.Len(t, applied, 2)
+		assert.Equal(t, "apiVersion: v2\nname: foo\nother: bar\n", string(upgraded))
+	})
+
+	t.Run("Should only apply pending migrations", func(t *testing.T) {
+		_, applied, err := testRegistry().UpgradeBytes([]byte("apiVersion: v1\ntitle: foo\n"))
+		assert.NoError(t, err)
+		assert.Len(t, applied, 1)
+		assert.Equal(t, "v2", applied[0].To)
+	})
+
+	t.Run("Should leave current documents untouched", func(t *testing.T) {
+		content := []byte("apiVersion: v2\n# comment\nname: foo\n")
+		upgraded, applied, err := testRegistry().UpgradeBytes(content)
+		assert.NoError(t, err)
+		assert.Len(t, applied, 0)
+		assert.Equal(t, content, upgraded)
+	})
+
+	t.Run("Should reject documents newer than supported", func(t *testing.T) {
+		_, _, err := testRegistry().UpgradeBytes([]byte("apiVersion: v3\nname: foo\n"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "test file uses apiVersion v3 but this version of Zero only supports up to v2")
+	})
+
+	t.Run("Should reject invalid versions", func(t *testing.T) {
+		_, _, err := testRegistry().UpgradeBytes([]byte("apiVersion: latest\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestMigrateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "config.yml")
+	original := []byte("title: foo\n")
+	assert.NoError(t, ioutil.WriteFile(filePath, original, 0644))
+
+	applied, backupPath, err := testRegistry().MigrateFile(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+
+	backup, err := ioutil.ReadFile(backupPath)
+	assert.NoError(t, err)
+	assert.Equal(t, original, backup)
+
+	migrated, err := ioutil.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: v2\nname: foo\n", string(migrated))
+
+	applied, backupPath, err = testRegistry().MigrateFile(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 0)
+	assert.Equal(t, "", backupPath)
+}
@@ -0,0 +1,163 @@
+package moduleconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+)
+
+// CredentialField is a value of a vendor's credentials, stored as a secret parameter of the modules requiring it
+type CredentialField struct {
+	Field           string   `yaml:"field"`
+	Label           string   `yaml:"label,omitempty"`
+	EnvVarName      string   `yaml:"envVarName,omitempty"`
+	FieldValidation Validate `yaml:"fieldValidation,omitempty"`
+}
+
+// CredentialProvider declares the credentials of a vendor
+type CredentialProvider struct {
+	Label  string            `yaml:"label,omitempty"`
+	Fields []CredentialField `yaml:"fields"`
+}
+
+// CredentialProviders are the built-in providers, keyed by the vendor used in requiredCredentials.
+// Modules can declare other vendors with credentialProviders.
+var CredentialProviders = map[string]CredentialProvider{
+	"aws": {
+		Label: "AWS",
+		Fields: []CredentialField{
+			{
+				Field:      "accessKeyId",
+				Label:      "AWS Access Key ID",
+				EnvVarName: "AWS_ACCESS_KEY_ID",
+				// 20 uppercase alphanumeric characters
+				FieldValidation: Validate{Type: constants.RegexValidation, Value: `^[A-Z0-9]{20}$`, ErrorMessage: "Invalid aws_access_key_id"},
+			},
+			{
+				Field:      "secretAccessKey",
+				Label:      "AWS Secret Access Key",
+				EnvVarName: "AWS_SECRET_ACCESS_KEY",
+				// 40 base64 characters
+				FieldValidation: Validate{Type: constants.RegexValidation, Value: `^[A-Za-z0-9/+=]{40}$`, ErrorMessage: "Invalid aws_secret_access_key"},
+			},
+		},
+	},
+	"github": {
+		Label: "GitHub",
+		Fields: []CredentialField{
+			{Field: "githubAccessToken", Label: "GitHub Personal Access Token", EnvVarName: "GITHUB_ACCESS_TOKEN"},
+		},
+	},
+	"circleci": {
+		Label: "CircleCI",
+		Fields: []CredentialField{
+			{Field: "circleciApiKey", Label: "CircleCI API Key", EnvVarName: "CIRCLECI_API_KEY"},
+		},
+	},
+}
+
+// Parameter returns the credential field as a secret parameter, to prompt and validate it like the module's parameters
+func (f CredentialField) Parameter() Parameter {
+	return Parameter{
+		Field:           f.Field,
+		Label:           f.Label,
+		EnvVarName:      f.EnvVarName,
+		FieldValidation: f.FieldValidation,
+		Secret:          true,
+	}
+}
+
+// GetCredentialProvider returns the provider of a vendor, the module's own providers take precedence over the built-in ones
+func (cfg ModuleConfig) GetCredentialProvider(vendor string) (CredentialProvider, error) {
+	if provider, ok := cfg.CredentialProviders[vendor]; ok {
+		return provider, nil
+	}
+	if provider, ok := CredentialProviders[vendor]; ok {
+		return provider, nil
+	}
+	vendors := []string{}
+	for name := range CredentialProviders {
+		vendors = append(vendors, name)
+	}
+	for name := range cfg.CredentialProviders {
+		vendors = append(vendors, name)
+	}
+	sort.Strings(vendors)
+	return CredentialProvider{}, fmt.Errorf("unknown credential vendor %q, expected one of %s or a vendor declared in credentialProviders", vendor, strings.Join(vendors, ", "))
+}
+
+// CredentialFields returns the fields of all the credentials required by the module
+func (cfg ModuleConfig) CredentialFields() []CredentialField {
+	fields := []CredentialField{}
+	for _, vendor := range cfg.RequiredCredentials {
+		// unknown vendors are rejected when the module is loaded
+		provider, _ := cfg.GetCredentialProvider(vendor)
+		fields = append(fields, provider.Fields...)
+	}
+	return fields
+}
+
+// ValidateCredentials checks the project supplies all the credentials required by the module, with valid values
+func (cfg ModuleConfig) ValidateCredentials(params projectconfig.Parameters) error {
+	messages := []string{}
+	for _, vendor := range cfg.RequiredCredentials {
+		provider, err := cfg.GetCredentialProvider(vendor)
+		if err != nil {
+			return err
+		}
+		missing := []string{}
+		for _, field := range provider.Fields {
+			value, ok := params[field.Field]
+			if !ok || projectconfig.FormatValue(value) == "" {
+				missing = append(missing, field.Field)
+			} else if err := field.Parameter().ValidateValue(value, nil); err != nil {
+				messages = append(messages, fmt.Sprintf("%s: %s: %v", vendor, field.Field, err))
+			}
+		}
+		if len(missing) > 0 {
+			messages = append(messages, fmt.Sprintf("%s: missing %s", vendor, strings.Join(missing, ", ")))
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("requires credentials:\n\t%s", strings.Join(messages, "\n\t"))
+	}
+	return nil
+}
+
+// SummarizeCredentials returns the credentials required by the module from the values gathered during `zero init`,
+// they are stored in the project's encrypted secrets file. Credentials the module declares as parameters
+// are summarized with its parameters instead
+func SummarizeCredentials(module ModuleConfig, allParams map[string]string) projectconfig.Parameters {
+	declared := map[string]bool{}
+	for _, parameter := range module.Parameters {
+		declared[parameter.Field] = true
+	}
+	credentials := projectconfig.Parameters{}
+	for _, field := range module.CredentialFields() {
+		if declared[field.Field] {
+			continue
+		}
+		if value, ok := allParams[field.Field]; ok && value != "" {
+			credentials[field.Field] = value
+		}
+	}
+	return credentials
+}
+
+// ReadVendorCredentialsFromModule uses parsed project-config's module
+// based on vendor parameter, retrieve the vendor's credential
+// for pre-defined functionalities (eg: Github api key for pushing repos to github)
+func ReadVendorCredentialsFromModule(m projectconfig.Module, vendor string) (error, string) {
+	provider, ok := CredentialProviders[vendor]
+	if !ok || len(provider.Fields) == 0 {
+		return errors.New("Unsupported vendor provided."), ""
+	}
+	if val, ok := m.Parameters[provider.Fields[0].Field]; ok {
+		return nil, projectconfig.FormatValue(val)
+	}
+	return errors.New("Parameter not found in module."), ""
+}
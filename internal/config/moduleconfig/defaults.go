@@ -0,0 +1,180 @@
+package moduleconfig
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/commitdev/zero/internal/util"
+)
+
+// References returns the fields the templates of the parameter's default and value refer to, eg: `.domain`
+func (p Parameter) References() ([]string, error) {
+	references := []string{}
+	for _, text := range []string{p.Default, p.Value} {
+		if !strings.Contains(text, "{{") {
+			continue
+		}
+		tmpl, err := parseParameterTemplate(p.Field, text)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range templateFields(tmpl.Tree.Root, true) {
+			if !util.ItemInSlice(references, field) {
+				references = append(references, field)
+			}
+		}
+	}
+	return references, nil
+}
+
+// RenderTemplates returns the parameter with its default and value rendered against the answers collected so far,
+// fields without a value render as an empty string
+func (p Parameter) RenderTemplates(values map[string]string) (Parameter, error) {
+	var err error
+	if p.Default, err = renderParameterTemplate(p.Field, p.Default, values); err != nil {
+		return p, err
+	}
+	if p.Value, err = renderParameterTemplate(p.Field, p.Value, values); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// OrderedParameters returns the parameters in the order they should be prompted:
+// the order of zero-module.yml, except parameters are moved after the ones their templates refer to
+func (cfg ModuleConfig) OrderedParameters() ([]Parameter, error) {
+	declared := map[string]bool{}
+	for _, parameter := range cfg.Parameters {
+		declared[parameter.Field] = true
+	}
+	dependencies := map[string][]string{}
+	for _, parameter := range cfg.Parameters {
+		references, err := parameter.References()
+		if err != nil {
+			return nil, err
+		}
+		for _, reference := range references {
+			// references to parameters the module doesn't declare, eg: the project name or another module's
+			// parameters, are answered before the module is prompted, see promptAllModules
+			if declared[reference] && reference != parameter.Field {
+				dependencies[parameter.Field] = append(dependencies[parameter.Field], reference)
+			}
+		}
+	}
+
+	ordered := []Parameter{}
+	placed := map[string]bool{}
+	for len(ordered) < len(cfg.Parameters) {
+		progress := false
+		for _, parameter := range cfg.Parameters {
+			if placed[parameter.Field] {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies[parameter.Field] {
+				if !placed[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, parameter)
+				placed[parameter.Field] = true
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			cycle := []string{}
+			for _, parameter := range cfg.Parameters {
+				if !placed[parameter.Field] {
+					cycle = append(cycle, parameter.Field)
+				}
+			}
+			return nil, fmt.Errorf("parameters reference each other in a cycle: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+func parseParameterTemplate(field string, text string) (*template.Template, error) {
+	tmpl, err := template.New(field).Funcs(util.FuncMap).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parameters.%s: invalid template: %v", field, err)
+	}
+	return tmpl, nil
+}
+
+func renderParameterTemplate(field string, text string, values map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := parseParameterTemplate(field, text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return "", fmt.Errorf("parameters.%s: %v", field, err)
+	}
+	return out.String(), nil
+}
+
+// templateFields returns the first identifier of the fields of the template's dot, eg: `domain` for `.domain`.
+// Inside range and with the dot is not the parameters, only the fields of `$` are returned, eg: `$.domain`
+func templateFields(node parse.Node, dotIsParams bool) []string {
+	fields := []string{}
+	walk := func(dotIsParams bool, children ...parse.Node) {
+		for _, child := range children {
+			fields = append(fields, templateFields(child, dotIsParams)...)
+		}
+	}
+	add := func(children ...parse.Node) {
+		walk(dotIsParams, children...)
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return fields
+		}
+		for _, child := range n.Nodes {
+			add(child)
+		}
+	case *parse.ActionNode:
+		add(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return fields
+		}
+		for _, cmd := range n.Cmds {
+			add(cmd)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			add(arg)
+		}
+	case *parse.FieldNode:
+		if dotIsParams {
+			fields = append(fields, n.Ident[0])
+		}
+	case *parse.VariableNode:
+		// $.field
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			fields = append(fields, n.Ident[1])
+		}
+	case *parse.IfNode:
+		add(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		// the dot of the body is the item, not the parameters
+		add(n.Pipe, n.ElseList)
+		walk(false, n.List)
+	case *parse.WithNode:
+		add(n.Pipe, n.ElseList)
+		walk(false, n.List)
+	}
+	return fields
+}
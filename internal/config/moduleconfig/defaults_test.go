@@ -0,0 +1,71 @@
+package moduleconfig_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedParameters(t *testing.T) {
+	t.Run("Parameters are moved after the ones they reference", func(t *testing.T) {
+		module := moduleconfig.ModuleConfig{
+			Parameters: []moduleconfig.Parameter{
+				{Field: "bucket", Value: "{{ range .regions }}{{ .name }}-{{ $.hostname }}{{ end }}"},
+				{Field: "hostname", Default: "{{ .subdomain }}.{{ .domain }}"},
+				{Field: "region"},
+				{Field: "subdomain", Value: "{{ .projectName }}"},
+				{Field: "domain"},
+			},
+		}
+		ordered, err := module.OrderedParameters()
+		assert.NoError(t, err)
+		fields := []string{}
+		for _, parameter := range ordered {
+			fields = append(fields, parameter.Field)
+		}
+		assert.Equal(t, []string{"region", "subdomain", "domain", "hostname", "bucket"}, fields)
+	})
+
+	t.Run("Cycles return error", func(t *testing.T) {
+		module := moduleconfig.ModuleConfig{
+			Parameters: []moduleconfig.Parameter{
+				{Field: "a", Default: "{{ .b }}"},
+				{Field: "b", Value: "{{ .a }}"},
+				{Field: "c"},
+			},
+		}
+		_, err := module.OrderedParameters()
+		assert.EqualError(t, err, "parameters reference each other in a cycle: a, b")
+	})
+
+	t.Run("Invalid templates return error", func(t *testing.T) {
+		module := moduleconfig.ModuleConfig{
+			Parameters: []moduleconfig.Parameter{
+				{Field: "a", Default: "{{ .b "},
+			},
+		}
+		_, err := module.OrderedParameters()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "parameters.a: invalid template")
+	})
+}
+
+func TestReferences(t *testing.T) {
+	parameter := moduleconfig.Parameter{
+		Field:   "bucket",
+		Default: "{{ with .region }}{{ .name }}-{{ $.domain }}{{ end }}",
+		Value:   "{{ range .regions }}{{ .zone }}{{ else }}{{ .fallback }}{{ end }}",
+	}
+	references, err := parameter.References()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"region", "domain", "regions", "fallback"}, references, "the dot of range and with bodies is not the parameters")
+}
+
+func TestRenderTemplates(t *testing.T) {
+	parameter := moduleconfig.Parameter{Field: "hostname", Default: "{{ .subdomain }}.{{ .domain }}", Value: "static"}
+	rendered, err := parameter.RenderTemplates(map[string]string{"domain": "example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, ".example.com", rendered.Default, "missing values render empty")
+	assert.Equal(t, "static", rendered.Value)
+}
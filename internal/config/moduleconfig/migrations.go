@@ -0,0 +1,22 @@
+package moduleconfig
+
+import (
+	"github.com/commitdev/zero/internal/config/migration"
+)
+
+// CurrentAPIVersion is the latest version of the zero-module.yml format supported by this version of Zero
+const CurrentAPIVersion = "v1"
+
+// Migrations upgrade module files written for older versions of Zero,
+// add a migration here whenever the format of zero-module.yml changes
+var Migrations = migration.Registry{
+	Kind:    "module",
+	Current: CurrentAPIVersion,
+	Migrations: []migration.Migration{
+		{
+			From:        migration.LegacyVersion,
+			To:          "v1",
+			Description: "Add apiVersion to unversioned module files",
+		},
+	},
+}
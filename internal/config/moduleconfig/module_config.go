@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"reflect"
 	"strings"
 
@@ -12,21 +11,25 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/config/schema"
 	"github.com/commitdev/zero/internal/constants"
 	"github.com/commitdev/zero/pkg/util/flog"
 	"github.com/commitdev/zero/version"
-	"github.com/iancoleman/strcase"
 )
 
 type ModuleConfig struct {
+	APIVersion          string `yaml:"apiVersion,omitempty"`
 	Name                string
 	Description         string
 	Author              string
+	Icon                string         `yaml:"icon,omitempty"`
+	Thumbnail           string         `yaml:"thumbnail,omitempty"`
 	Commands            ModuleCommands `yaml:"commands,omitempty"`
 	DependsOn           []string       `yaml:"dependsOn,omitempty"`
 	TemplateConfig      `yaml:"template"`
-	RequiredCredentials []string           `yaml:"requiredCredentials"`
-	ZeroVersion         VersionConstraints `yaml:"zeroVersion,omitempty"`
+	RequiredCredentials []string                      `yaml:"requiredCredentials"`
+	CredentialProviders map[string]CredentialProvider `yaml:"credentialProviders,omitempty"`
+	ZeroVersion         VersionConstraints            `yaml:"zeroVersion,omitempty"`
 	Parameters          []Parameter
 	Conditions          []Condition `yaml:"conditions,omitempty"`
 }
@@ -37,6 +40,13 @@ type ModuleCommands struct {
 	Summary string `yaml:"summary,omitempty"`
 }
 
+// DefaultCommands are run by `zero apply` for the commands a module doesn't define
+var DefaultCommands = ModuleCommands{
+	Apply:   "make",
+	Check:   "make check",
+	Summary: "make summary",
+}
+
 func checkVersionAgainstConstrains(vc VersionConstraints, versionString string) bool {
 	v, err := goVerson.NewVersion(versionString)
 	if err != nil {
@@ -75,15 +85,18 @@ type Parameter struct {
 	FieldValidation     Validate      `yaml:"fieldValidation,omitempty"`
 	Type                string        `yaml:"type,omitempty"`
 	OmitFromProjectFile bool          `yaml:"omitFromProjectFile,omitempty"`
+	Secret              bool          `yaml:"secret,omitempty"`
 	Conditions          []Condition   `yaml:"conditions,omitempty"`
 	EnvVarName          string        `yaml:"envVarName,omitempty"`
 }
 
+// ActionKeyMatchCondition is the action of parameter conditions, the prompt only runs when the condition is met
+const ActionKeyMatchCondition = "KeyMatchCondition"
+
 type Condition struct {
-	Action     string   `yaml:"action"`
-	MatchField string   `yaml:"matchField"`
-	WhenValue  string   `yaml:"whenValue"`
-	Data       []string `yaml:"data,omitempty"`
+	Action                   string `yaml:"action"`
+	projectconfig.Expression `yaml:",inline"`
+	Data                     []string `yaml:"data,omitempty"`
 }
 
 type Validate struct {
@@ -93,22 +106,36 @@ type Validate struct {
 }
 
 type TemplateConfig struct {
-	StrictMode bool
-	Delimiters []string
-	InputDir   string `yaml:"inputDir"`
-	OutputDir  string `yaml:"outputDir"`
+	StrictMode  bool       `yaml:"strictMode,omitempty"`
+	Delimiters  []string   `yaml:"delimiters,omitempty"`
+	InputDir    string     `yaml:"inputDir"`
+	OutputDir   string     `yaml:"outputDir"`
+	PartialsDir string     `yaml:"partialsDir,omitempty"`
+	MissingKey  MissingKey `yaml:"missingKey,omitempty"`
 }
 
+// MissingKey is how templates handle references to keys missing from a map such as .Params,
+// it is passed to text/template as the missingkey option
+type MissingKey string
+
+const (
+	// MissingKeyDefault renders missing keys as "<no value>", it is used when missingKey is not set
+	MissingKeyDefault MissingKey = "default"
+	// MissingKeyZero renders missing keys as empty strings
+	MissingKeyZero MissingKey = "zero"
+	// MissingKeyError fails the rendering of templates referencing missing keys
+	MissingKeyError MissingKey = "error"
+)
+
 type VersionConstraints struct {
 	goVerson.Constraints
 }
 
-// A "nice" wrapper around findMissing()
-func (cfg ModuleConfig) collectMissing() []string {
-	var missing []string
-	findMissing(reflect.ValueOf(cfg), "", "", &missing)
+var moduleSchema = schema.Generate(constants.ZeroModuleYml, reflect.TypeOf(ModuleConfig{}))
 
-	return missing
+// Schema returns the JSON Schema of zero-module.yml, it is used to validate modules when they are loaded
+func Schema() *schema.Schema {
+	return moduleSchema
 }
 
 // GetParamEnvVarTranslationMap returns a map for translating parameter's `Field` into env-var keys
@@ -117,6 +144,12 @@ func (cfg ModuleConfig) collectMissing() []string {
 // this is useful for translating params like AWS credentials for running the AWS cli
 func (cfg ModuleConfig) GetParamEnvVarTranslationMap() map[string]string {
 	translationMap := make(map[string]string)
+	// credentials use their provider's env var names, unless the module overrides them
+	for _, field := range cfg.CredentialFields() {
+		if field.EnvVarName != "" {
+			translationMap[field.Field] = field.EnvVarName
+		}
+	}
 	for i := 0; i < len(cfg.Parameters); i++ {
 		param := cfg.Parameters[i]
 		if param.EnvVarName != "" {
@@ -134,20 +167,31 @@ func LoadModuleConfig(filePath string) (ModuleConfig, error) {
 		return config, err
 	}
 
-	err = yaml.Unmarshal(data, &config)
+	data, _, err = Migrations.UpgradeBytes(data)
 	if err != nil {
-		return config, err
+		return config, fmt.Errorf("failed to load %s: %v", filePath, err)
 	}
 
-	missing := config.collectMissing()
-	if len(missing) > 0 {
-		flog.Errorf("%v is missing information", filePath)
-
-		for _, m := range missing {
-			flog.Errorf("\t %v", m)
-		}
+	var doc interface{}
+	err = yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return config, err
+	}
+	// unknown fields are only warned about, the module may have been written for a newer version of Zero
+	errs, unknown := Schema().ValidateLenient(doc)
+	for _, field := range unknown {
+		flog.Warnf("%s: %s", filePath, field)
+	}
+	if len(errs) > 0 {
+		return config, fmt.Errorf("%s is invalid:%v", filePath, errs)
+	}
 
-		log.Fatal("")
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return config, err
+	}
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("%s is invalid:\n\t%v", filePath, err)
 	}
 
 	if !ValidateZeroVersion(config) {
@@ -160,79 +204,70 @@ Please check %s for available releases.`
 	return config, nil
 }
 
-// Recurses through a datastructure to find any missing data.
-// This assumes several things:
-// 1. The structure matches that defined by ModuleConfig and its child datastructures.
-// 2. YAML struct field metadata is sufficient to define whether an attribute is missing or not.
-//    That is, "yaml:foo,omitempty" tells us this is not a required field because we can omit it.
-// 3. Slices and arrays are assumed to be optional.
-//
-// As this function recurses through the datastructure, it builds up a string
-// path representing each node's path within the datastructure.
-// If the value of the current node is equal to the zero value for its datatype
-// and its struct field does *not* have a "omitempty" value, then we assume it
-// is missing and add it to the resultset.
-func findMissing(obj reflect.Value, path, metadata string, missing *[]string) {
-	t := obj.Type()
-	switch t.Kind() {
-	case reflect.String:
-		if obj.String() == "" && !strings.Contains(metadata, "omitempty") {
-			*missing = append(*missing, path)
-		}
-
-	case reflect.Slice, reflect.Array:
-		for i := 0; i < obj.Len(); i++ {
-			prefix := fmt.Sprintf("%v[%v]", path, i)
-			findMissing(obj.Index(i), prefix, metadata, missing)
+// validate checks the parts of the module the schema can't describe, so mistakes are reported when it is loaded
+func (cfg ModuleConfig) validate() error {
+	if _, err := cfg.OrderedParameters(); err != nil {
+		return err
+	}
+	for _, vendor := range cfg.RequiredCredentials {
+		if _, err := cfg.GetCredentialProvider(vendor); err != nil {
+			return fmt.Errorf("requiredCredentials: %v", err)
 		}
-
-	case reflect.Struct:
-		for i := 0; i < t.NumField(); i++ {
-			fieldType := t.Field(i)
-			fieldTags, _ := fieldType.Tag.Lookup("yaml")
-			fieldVal := obj.Field(i)
-
-			tags := strings.Split(fieldTags, ",")
-
-			hasOmitEmpty := false
-			// We have all metadata yaml tags, now let's remove the "omitempty" tag if
-			// it is present.
-			// Then if we have only one tag remaining, this must be the expected yaml
-			// identifer.
-			// Otherwise the name of the yaml identifier should match the struct
-			// attribute name.
-			for i := len(tags) - 1; i >= 0; i-- {
-				tag := tags[i]
-				if tag == "omitempty" {
-					hasOmitEmpty = true
-					tags = append(tags[:i], tags[i+1:]...)
-				}
-			}
-
-			yamlName := strcase.ToLowerCamel(fieldType.Name)
-			if len(tags) == 1 && tags[0] != "" { // For some reason, empty tag lists are giving a count of 1.
-				yamlName = tags[0]
-			}
-
-			prefix := yamlName
-			if path != "" {
-				prefix = fmt.Sprintf("%v.%v", path, yamlName)
+	}
+	for vendor, provider := range cfg.CredentialProviders {
+		for _, field := range provider.Fields {
+			if err := field.FieldValidation.check(); err != nil {
+				return fmt.Errorf("credentialProviders.%s.%s.fieldValidation: %v", vendor, field.Field, err)
 			}
-
-			zeroVal := reflect.Zero(fieldType.Type)
-			if fieldVal == zeroVal && !hasOmitEmpty {
-				*missing = append(*missing, prefix)
+		}
+	}
+	for _, parameter := range cfg.Parameters {
+		if err := parameter.FieldValidation.check(); err != nil {
+			return fmt.Errorf("parameters.%s.fieldValidation: %v", parameter.Field, err)
+		}
+		for i, condition := range parameter.Conditions {
+			if err := condition.check([]string{ActionKeyMatchCondition}); err != nil {
+				return fmt.Errorf("parameters.%s.conditions[%d]: %v", parameter.Field, i, err)
 			}
-
-			findMissing(fieldVal, prefix, fieldTags, missing)
 		}
 	}
+	for i, condition := range cfg.Conditions {
+		if err := condition.check(projectconfig.ConditionActions); err != nil {
+			return fmt.Errorf("conditions[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (c Condition) check(actions []string) error {
+	found := false
+	for _, action := range actions {
+		found = found || action == c.Action
+	}
+	if !found {
+		return fmt.Errorf("unsupported action %q, expected one of %s", c.Action, strings.Join(actions, ", "))
+	}
+	if err := projectconfig.CheckConditionData(c.Action, c.Data); err != nil {
+		return err
+	}
+	return c.Expression.Validate()
 }
 
 // SummarizeParameters receives all parameters gathered from prompts during `Zero init`
 // and based on module definition to construct the parameters for each module for zero-project.yml
-// filters out parameters defined as OmitFromProjectFile: true
-func SummarizeParameters(module ModuleConfig, allParams map[string]string) map[string]string {
+// filters out parameters defined as OmitFromProjectFile: true and secrets,
+// values are converted from their prompted string form to the parameter's type
+func SummarizeParameters(module ModuleConfig, allParams map[string]string) projectconfig.Parameters {
+	return summarize(module, allParams, false)
+}
+
+// SummarizeSecrets is the counterpart of SummarizeParameters for parameters defined as secret: true,
+// these are stored in the project's encrypted secrets file instead of zero-project.yml
+func SummarizeSecrets(module ModuleConfig, allParams map[string]string) projectconfig.Parameters {
+	return summarize(module, allParams, true)
+}
+
+func summarize(module ModuleConfig, allParams map[string]string, secrets bool) projectconfig.Parameters {
 	moduleParams := make(projectconfig.Parameters)
 	// Loop through all the prompted values and find the ones relevant to this module
 	for parameterKey, parameterValue := range allParams {
@@ -240,8 +275,13 @@ func SummarizeParameters(module ModuleConfig, allParams map[string]string) map[s
 			if moduleParameter.Field == parameterKey {
 				if moduleParameter.OmitFromProjectFile {
 					flog.Debugf("Omitted %s from %s", parameterKey, module.Name)
-				} else {
-					moduleParams[parameterKey] = parameterValue
+				} else if moduleParameter.Secret == secrets {
+					value, err := moduleParameter.ParseValue(parameterValue)
+					if err != nil {
+						flog.Warnf("Keeping %s of %s as a string: %v", parameterKey, module.Name, err)
+						value = parameterValue
+					}
+					moduleParams[parameterKey] = value
 				}
 			}
 		}
@@ -257,14 +297,23 @@ func SummarizeConditions(module ModuleConfig) []projectconfig.Condition {
 	for i, condition := range module.Conditions {
 		moduleConditions[i] = projectconfig.Condition{
 			Action:     condition.Action,
-			MatchField: condition.MatchField,
-			WhenValue:  condition.WhenValue,
+			Expression: condition.Expression,
 			Data:       condition.Data,
 		}
 	}
 	return moduleConditions
 }
 
+// JSONSchema restricts missingKey to the options supported by text/template
+func (MissingKey) JSONSchema() *schema.Schema {
+	return &schema.Schema{Type: "string", Enum: []interface{}{string(MissingKeyDefault), string(MissingKeyZero), string(MissingKeyError)}}
+}
+
+// JSONSchema describes version constraints as they are written in zero-module.yml
+func (VersionConstraints) JSONSchema() *schema.Schema {
+	return &schema.Schema{Type: "string"}
+}
+
 // UnmarshalYAML Parses a version constraint string into go-version constraint during yaml parsing
 func (semVer *VersionConstraints) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var versionString string
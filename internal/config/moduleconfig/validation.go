@@ -0,0 +1,261 @@
+package moduleconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/internal/util"
+)
+
+var (
+	dnsLabelPattern  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]*)?-[a-z]+-[0-9]$`)
+)
+
+// validator checks a value, arg is the `value` of the fieldValidation
+type validator func(value string, arg string) error
+
+var validators = map[string]validator{
+	constants.RegexValidation:     validateRegex,
+	constants.URLValidation:       validateURL,
+	constants.EmailValidation:     validateEmail,
+	constants.DomainValidation:    validateDomain,
+	constants.DNSLabelValidation:  validateDNSLabel,
+	constants.CIDRValidation:      validateCIDR,
+	constants.PortValidation:      validatePort,
+	constants.AWSRegionValidation: validateAWSRegion,
+	constants.MinLengthValidation: validateMinLength,
+	constants.MaxLengthValidation: validateMaxLength,
+	constants.RangeValidation:     validateRange,
+}
+
+// ValidationTypes returns the names of the supported field validations
+func ValidationTypes() []string {
+	types := []string{constants.FunctionValidation}
+	for name := range validators {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// check makes sure the validation is supported and its value is usable, so module mistakes are reported on load
+func (v Validate) check() error {
+	switch v.Type {
+	case "":
+		return nil
+	case constants.FunctionValidation:
+		if v.Value == "" {
+			return errors.New("function validation requires a command as value")
+		}
+		return nil
+	case constants.RegexValidation:
+		_, err := regexp.Compile(v.Value)
+		return err
+	case constants.MinLengthValidation, constants.MaxLengthValidation:
+		_, err := strconv.Atoi(v.Value)
+		return err
+	case constants.RangeValidation:
+		_, _, err := parseRange(v.Value)
+		return err
+	}
+	if _, ok := validators[v.Type]; !ok {
+		return fmt.Errorf("unsupported validation type %q, expected one of %s", v.Type, strings.Join(ValidationTypes(), ", "))
+	}
+	return nil
+}
+
+// ValidateValue checks a value against the parameter's fieldValidation,
+// items of lists and values of maps are validated individually.
+// env holds the other parameter values, they are available to function validations as env vars.
+func (p Parameter) ValidateValue(value interface{}, env map[string]string) error {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := p.ValidateValue(item, env); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		for _, item := range v {
+			if err := p.ValidateValue(item, env); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	validation := p.FieldValidation
+	if validation.Type == "" {
+		return nil
+	}
+	input := projectconfig.FormatValue(value)
+	var err error
+	if validation.Type == constants.FunctionValidation {
+		err = validateFunction(input, validation.Value, env)
+	} else if validate, ok := validators[validation.Type]; ok {
+		err = validate(input, validation.Value)
+	} else {
+		return fmt.Errorf("unsupported validation type %q", validation.Type)
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	// the stderr of a function takes precedence, the module's errorMessage over the built-in messages
+	case validation.Type == constants.FunctionValidation && err.Error() != "":
+		return err
+	case validation.ErrorMessage != "":
+		return errors.New(validation.ErrorMessage)
+	case err.Error() == "":
+		return fmt.Errorf("%s failed validation", p.Field)
+	}
+	return err
+}
+
+// ValidateParameters checks the values supplied by a project against the module's parameter validations
+func (cfg ModuleConfig) ValidateParameters(params projectconfig.Parameters) error {
+	env := params.Strings()
+	messages := []string{}
+	for _, parameter := range cfg.Parameters {
+		value, ok := params[parameter.Field]
+		if !ok {
+			continue
+		}
+		if err := parameter.ValidateValue(value, env); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", parameter.Field, err))
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("invalid parameters for module %s:\n\t%s", cfg.Name, strings.Join(messages, "\n\t"))
+	}
+	return nil
+}
+
+// validateFunction runs the command with the value in the VALUE env var and on stdin,
+// a non-zero exit code means the value is invalid and stderr is the error message
+func validateFunction(value string, command string, env map[string]string) error {
+	cmd := exec.Command("bash", "-c", command)
+	cmd.Env = util.AppendProjectEnvToCmdEnv(env, os.Environ(), map[string]string{})
+	cmd.Env = append(cmd.Env, fmt.Sprintf("VALUE=%s", value))
+	cmd.Stdin = strings.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func validateRegex(value string, pattern string) error {
+	if !regexp.MustCompile(pattern).MatchString(value) {
+		return fmt.Errorf("must match %s", pattern)
+	}
+	return nil
+}
+
+func validateURL(value string, _ string) error {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("must be a URL, eg: https://example.com")
+	}
+	return nil
+}
+
+func validateEmail(value string, _ string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return errors.New("must be an email address")
+	}
+	return nil
+}
+
+func validateDomain(value string, _ string) error {
+	labels := strings.Split(strings.TrimSuffix(value, "."), ".")
+	if len(value) > 253 || len(labels) < 2 {
+		return errors.New("must be a domain name, eg: example.com")
+	}
+	for _, label := range labels {
+		if !dnsLabelPattern.MatchString(strings.ToLower(label)) {
+			return errors.New("must be a domain name, eg: example.com")
+		}
+	}
+	return nil
+}
+
+func validateDNSLabel(value string, _ string) error {
+	if !dnsLabelPattern.MatchString(value) {
+		return errors.New("must be a DNS label: up to 63 lowercase alphanumeric characters or '-', starting and ending with an alphanumeric character")
+	}
+	return nil
+}
+
+func validateCIDR(value string, _ string) error {
+	if _, _, err := net.ParseCIDR(value); err != nil {
+		return errors.New("must be a CIDR block, eg: 10.0.0.0/16")
+	}
+	return nil
+}
+
+func validatePort(value string, _ string) error {
+	return validateRange(value, "1..65535")
+}
+
+func validateAWSRegion(value string, _ string) error {
+	if !awsRegionPattern.MatchString(value) {
+		return errors.New("must be an AWS region, eg: us-west-2")
+	}
+	return nil
+}
+
+func validateMinLength(value string, arg string) error {
+	min, _ := strconv.Atoi(arg)
+	if len(value) < min {
+		return fmt.Errorf("must be at least %d characters", min)
+	}
+	return nil
+}
+
+func validateMaxLength(value string, arg string) error {
+	max, _ := strconv.Atoi(arg)
+	if len(value) > max {
+		return fmt.Errorf("must be at most %d characters", max)
+	}
+	return nil
+}
+
+func validateRange(value string, arg string) error {
+	min, max, _ := parseRange(arg)
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return fmt.Errorf("must be an integer between %d and %d", min, max)
+	}
+	return nil
+}
+
+// parseRange parses an inclusive integer range written as min..max
+func parseRange(arg string) (int, int, error) {
+	bounds := strings.Split(arg, "..")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid range %q, expected min..max", arg)
+	}
+	min, minErr := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	max, maxErr := strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if minErr != nil || maxErr != nil || min > max {
+		return 0, 0, fmt.Errorf("invalid range %q, expected min..max", arg)
+	}
+	return min, max, nil
+}
@@ -0,0 +1,83 @@
+package moduleconfig_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func validatedParameter(validationType string, value string) moduleconfig.Parameter {
+	return moduleconfig.Parameter{
+		Field:           "field",
+		FieldValidation: moduleconfig.Validate{Type: validationType, Value: value},
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	cases := []struct {
+		validationType string
+		arg            string
+		valid          []interface{}
+		invalid        []interface{}
+	}{
+		{constants.RegexValidation, "^[a-z]+$", []interface{}{"abc"}, []interface{}{"ABC"}},
+		{constants.URLValidation, "", []interface{}{"https://example.com/path"}, []interface{}{"example.com", "https://"}},
+		{constants.EmailValidation, "", []interface{}{"me@example.com"}, []interface{}{"me", "Me <me@example.com>"}},
+		{constants.DomainValidation, "", []interface{}{"api.example.com"}, []interface{}{"localhost", "-bad.example.com"}},
+		{constants.DNSLabelValidation, "", []interface{}{"my-service"}, []interface{}{"My_Service", "-service"}},
+		{constants.CIDRValidation, "", []interface{}{"10.0.0.0/16"}, []interface{}{"10.0.0.0"}},
+		{constants.PortValidation, "", []interface{}{"8080", 443}, []interface{}{"0", "65536", "http"}},
+		{constants.AWSRegionValidation, "", []interface{}{"us-west-2", "us-gov-west-1"}, []interface{}{"us-west", "mars-1"}},
+		{constants.MinLengthValidation, "3", []interface{}{"abc"}, []interface{}{"ab"}},
+		{constants.MaxLengthValidation, "3", []interface{}{"abc"}, []interface{}{"abcd"}},
+		{constants.RangeValidation, "1..10", []interface{}{"1", 10}, []interface{}{"0", "11"}},
+		{constants.DNSLabelValidation, "", []interface{}{[]interface{}{"api", "www"}}, []interface{}{[]interface{}{"api", "WWW"}}},
+	}
+
+	for _, c := range cases {
+		parameter := validatedParameter(c.validationType, c.arg)
+		for _, value := range c.valid {
+			assert.NoError(t, parameter.ValidateValue(value, nil), "%s should accept %v", c.validationType, value)
+		}
+		for _, value := range c.invalid {
+			assert.Error(t, parameter.ValidateValue(value, nil), "%s should reject %v", c.validationType, value)
+		}
+	}
+}
+
+func TestValidateValueMessages(t *testing.T) {
+	t.Run("Should use the module's error message", func(t *testing.T) {
+		parameter := validatedParameter(constants.RegexValidation, "^[a-z]+$")
+		parameter.FieldValidation.ErrorMessage = "only lowercase letters"
+		assert.EqualError(t, parameter.ValidateValue("ABC", nil), "only lowercase letters")
+	})
+
+	t.Run("Should use the stderr of function validations", func(t *testing.T) {
+		parameter := validatedParameter(constants.FunctionValidation, `[ "$VALUE" != "$reserved" ] || (echo "$VALUE is reserved" >&2 && exit 1)`)
+		env := map[string]string{"reserved": "admin"}
+		assert.NoError(t, parameter.ValidateValue("bob", env))
+		assert.EqualError(t, parameter.ValidateValue("admin", env), "admin is reserved")
+	})
+
+	t.Run("Should fall back to a generic message for silent functions", func(t *testing.T) {
+		parameter := validatedParameter(constants.FunctionValidation, "grep -q ok")
+		assert.NoError(t, parameter.ValidateValue("ok", nil))
+		assert.EqualError(t, parameter.ValidateValue("nope", nil), "field failed validation")
+	})
+}
+
+func TestValidateParameters(t *testing.T) {
+	module := moduleconfig.ModuleConfig{
+		Name: "backend",
+		Parameters: []moduleconfig.Parameter{
+			validatedParameter(constants.PortValidation, ""),
+			{Field: "other", FieldValidation: moduleconfig.Validate{Type: constants.AWSRegionValidation}},
+		},
+	}
+	assert.NoError(t, module.ValidateParameters(projectconfig.Parameters{"field": 80}))
+	assert.EqualError(t, module.ValidateParameters(projectconfig.Parameters{"field": 80, "other": "moon"}),
+		"invalid parameters for module backend:\n\tother: must be an AWS region, eg: us-west-2")
+}
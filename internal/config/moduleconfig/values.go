@@ -0,0 +1,101 @@
+package moduleconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+)
+
+// Value types of parameters, any other `type` is a custom prompt (eg: AWSProfilePicker)
+const (
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeInt    = "int"
+	TypeList   = "list"
+	TypeMap    = "map"
+)
+
+// IsValueType returns whether the parameter's type describes its value rather than a custom prompt
+func (p Parameter) IsValueType() bool {
+	switch p.Type {
+	case "", TypeString, TypeBool, TypeInt, TypeList, TypeMap:
+		return true
+	}
+	return false
+}
+
+// ParseValue converts the string form of a value, as entered in a prompt or returned by `execute`,
+// into the parameter's type:
+// bool accepts true/false and yes/no, lists are a json array or comma separated items,
+// maps are a json object or comma separated key=value pairs
+func (p Parameter) ParseValue(input string) (interface{}, error) {
+	trimmed := strings.TrimSpace(input)
+	switch p.Type {
+	case TypeBool:
+		switch strings.ToLower(trimmed) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		value, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", p.Field)
+		}
+		return value, nil
+
+	case TypeInt:
+		value, err := strconv.Atoi(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", p.Field)
+		}
+		return value, nil
+
+	case TypeList:
+		list := []interface{}{}
+		if strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal([]byte(trimmed), &list); err != nil {
+				return nil, fmt.Errorf("%s must be a list: %v", p.Field, err)
+			}
+			return projectconfig.NormalizeValue(list), nil
+		}
+		for _, item := range splitItems(trimmed) {
+			list = append(list, item)
+		}
+		return list, nil
+
+	case TypeMap:
+		value := map[string]interface{}{}
+		if strings.HasPrefix(trimmed, "{") {
+			if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+				return nil, fmt.Errorf("%s must be a map: %v", p.Field, err)
+			}
+			return projectconfig.NormalizeValue(value), nil
+		}
+		for _, item := range splitItems(trimmed) {
+			pair := strings.SplitN(item, "=", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("%s must be a list of key=value pairs", p.Field)
+			}
+			value[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+		}
+		return value, nil
+	}
+
+	// aws cli prints output with linebreak in them
+	return strings.ReplaceAll(input, "\n", ""), nil
+}
+
+// splitItems splits a comma or newline separated list, ignoring empty items
+func splitItems(input string) []string {
+	items := []string{}
+	for _, item := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,138 @@
+package projectconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operators comparing the value of a condition's matchField
+const (
+	OperatorEquals    = "equals"
+	OperatorNotEquals = "notEquals"
+	OperatorIn        = "in"
+	OperatorNotIn     = "notIn"
+	OperatorMatches   = "matches"
+	OperatorExists    = "exists"
+	OperatorEmpty     = "empty"
+)
+
+// Operators lists the supported operators
+var Operators = []string{OperatorEquals, OperatorNotEquals, OperatorIn, OperatorNotIn, OperatorMatches, OperatorExists, OperatorEmpty}
+
+// Expression is the test of a condition, either a comparison of the value of MatchField
+// or a group combining other expressions with all / any / not.
+// The operator defaults to equals, comparing with WhenValue.
+type Expression struct {
+	MatchField string       `yaml:"matchField,omitempty"`
+	Operator   string       `yaml:"operator,omitempty"`
+	WhenValue  string       `yaml:"whenValue,omitempty"`
+	Values     []string     `yaml:"values,omitempty"`
+	All        []Expression `yaml:"all,omitempty"`
+	Any        []Expression `yaml:"any,omitempty"`
+	Not        *Expression  `yaml:"not,omitempty"`
+}
+
+// GetOperator returns the operator of a comparison, defaulting to equals
+func (e Expression) GetOperator() string {
+	if e.Operator == "" {
+		return OperatorEquals
+	}
+	return e.Operator
+}
+
+// Validate checks the expression is well formed: each expression is either a comparison or a single group,
+// with a supported operator and the values it needs
+func (e Expression) Validate() error {
+	kinds := 0
+	for _, isSet := range []bool{e.MatchField != "", e.All != nil, e.Any != nil, e.Not != nil} {
+		if isSet {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("a condition must have exactly one of matchField, all, any or not")
+	}
+
+	switch {
+	case e.All != nil:
+		return validateGroup("all", e.All)
+	case e.Any != nil:
+		return validateGroup("any", e.Any)
+	case e.Not != nil:
+		if err := e.Not.Validate(); err != nil {
+			return fmt.Errorf("not: %v", err)
+		}
+		return nil
+	}
+
+	switch e.GetOperator() {
+	case OperatorEquals, OperatorNotEquals, OperatorExists, OperatorEmpty:
+	case OperatorIn, OperatorNotIn:
+		if len(e.Values) == 0 {
+			return fmt.Errorf("operator %s requires values", e.Operator)
+		}
+	case OperatorMatches:
+		if _, err := regexp.Compile(e.WhenValue); err != nil {
+			return fmt.Errorf("operator matches requires a regular expression as whenValue: %v", err)
+		}
+	default:
+		return fmt.Errorf("unsupported operator %q, expected one of %s", e.Operator, strings.Join(Operators, ", "))
+	}
+	return nil
+}
+
+func validateGroup(name string, expressions []Expression) error {
+	if len(expressions) == 0 {
+		return fmt.Errorf("%s requires at least one condition", name)
+	}
+	for i, expression := range expressions {
+		if err := expression.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %v", name, i, err)
+		}
+	}
+	return nil
+}
+
+// String describes the expression for logs, eg: `all(region equals us-west-2, not(database empty))`
+func (e Expression) String() string {
+	switch {
+	case e.All != nil:
+		return groupString("all", e.All)
+	case e.Any != nil:
+		return groupString("any", e.Any)
+	case e.Not != nil:
+		return fmt.Sprintf("not(%s)", e.Not)
+	}
+	switch e.GetOperator() {
+	case OperatorExists, OperatorEmpty:
+		return fmt.Sprintf("%s %s", e.MatchField, e.GetOperator())
+	case OperatorIn, OperatorNotIn:
+		return fmt.Sprintf("%s %s [%s]", e.MatchField, e.GetOperator(), strings.Join(e.Values, ", "))
+	}
+	return fmt.Sprintf("%s %s %q", e.MatchField, e.GetOperator(), e.WhenValue)
+}
+
+// Fields returns the fields compared by the expression and its nested expressions
+func (e Expression) Fields() []string {
+	fields := []string{}
+	if e.MatchField != "" {
+		fields = append(fields, e.MatchField)
+	}
+	for _, expression := range append(append([]Expression{}, e.All...), e.Any...) {
+		fields = append(fields, expression.Fields()...)
+	}
+	if e.Not != nil {
+		fields = append(fields, e.Not.Fields()...)
+	}
+	return fields
+}
+
+func groupString(name string, expressions []Expression) string {
+	parts := make([]string, len(expressions))
+	for i, expression := range expressions {
+		parts[i] = expression.String()
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", "))
+}
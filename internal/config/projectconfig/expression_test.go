@@ -0,0 +1,51 @@
+package projectconfig_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressionValidate(t *testing.T) {
+	valid := []projectconfig.Expression{
+		{MatchField: "region", WhenValue: "us-west-2"},
+		{MatchField: "region", Operator: projectconfig.OperatorIn, Values: []string{"us-west-2"}},
+		{Any: []projectconfig.Expression{{MatchField: "a", Operator: projectconfig.OperatorExists}}},
+		{Not: &projectconfig.Expression{MatchField: "a", Operator: projectconfig.OperatorEmpty}},
+	}
+	for _, e := range valid {
+		assert.NoError(t, e.Validate(), e.String())
+	}
+
+	invalid := map[string]projectconfig.Expression{
+		"a condition must have exactly one of matchField, all, any or not":                                        {},
+		`unsupported operator "startsWith", expected one of equals, notEquals, in, notIn, matches, exists, empty`: {MatchField: "a", Operator: "startsWith"},
+		"operator in requires values":         {MatchField: "a", Operator: projectconfig.OperatorIn},
+		"all requires at least one condition": {All: []projectconfig.Expression{}},
+		"any[0]: a condition must have exactly one of matchField, all, any or not": {
+			Any: []projectconfig.Expression{{MatchField: "a", All: []projectconfig.Expression{{MatchField: "b"}}}},
+		},
+	}
+	for message, e := range invalid {
+		assert.EqualError(t, e.Validate(), message)
+	}
+	assert.Error(t, projectconfig.Expression{MatchField: "a", Operator: projectconfig.OperatorMatches, WhenValue: "("}.Validate())
+}
+
+func TestExpressionString(t *testing.T) {
+	e := projectconfig.Expression{All: []projectconfig.Expression{
+		{MatchField: "region", WhenValue: "us-west-2"},
+		{Not: &projectconfig.Expression{MatchField: "database", Operator: projectconfig.OperatorEmpty}},
+	}}
+	assert.Equal(t, `all(region equals "us-west-2", not(database empty))`, e.String())
+}
+
+func TestExpressionFields(t *testing.T) {
+	expression := projectconfig.Expression{All: []projectconfig.Expression{
+		{MatchField: "region", WhenValue: "us-west-2"},
+		{Any: []projectconfig.Expression{{MatchField: "a", Operator: projectconfig.OperatorExists}}},
+		{Not: &projectconfig.Expression{MatchField: "database", Operator: projectconfig.OperatorEmpty}},
+	}}
+	assert.Equal(t, []string{"region", "a", "database"}, expression.Fields())
+}
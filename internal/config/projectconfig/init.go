@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
+	"strings"
 	"text/template"
 
 	"github.com/commitdev/zero/internal/constants"
@@ -15,6 +17,8 @@ import (
 
 const zeroProjectConfigTemplate = `
 # Templated zero-project.yml file
+apiVersion: {{.APIVersion}}
+
 name: {{.Name}}
 
 shouldPushRepositories: {{.ShouldPushRepositories | printf "%v"}}
@@ -30,6 +34,7 @@ func SetRootDir(dir string) {
 }
 
 // CreateProjectConfigFile extracts the required content for zero project config file then write to disk.
+// Secret parameters are encrypted into a separate secrets file.
 func CreateProjectConfigFile(dir string, projectName string, projectContext *ZeroProjectConfig) error {
 	content, err := getProjectFileContent(*projectContext)
 	if err != nil {
@@ -40,10 +45,35 @@ func CreateProjectConfigFile(dir string, projectName string, projectContext *Zer
 	flog.Debugf("Project file path: %s", filePath)
 	writeErr := ioutil.WriteFile(filePath, []byte(content), 0644)
 	if writeErr != nil {
+		return writeErr
+	}
+
+	err = writeGitignore(path.Join(dir, projectName))
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return projectContext.writeSecrets(path.Join(dir, projectName))
+}
+
+// writeGitignore makes sure the personal config overrides are not committed with the project,
+// the entry is appended to an existing .gitignore unless it is already there
+func writeGitignore(dir string) error {
+	gitignorePath := path.Join(dir, ".gitignore")
+	content, err := ioutil.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == constants.ZeroProjectLocalYml {
+			return nil
+		}
+	}
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	content = append(content, []byte(constants.ZeroProjectLocalYml+"\n")...)
+	return ioutil.WriteFile(gitignorePath, content, 0644)
 }
 
 func getProjectFileContent(projectConfig ZeroProjectConfig) (string, error) {
@@ -57,16 +87,18 @@ func getProjectFileContent(projectConfig ZeroProjectConfig) (string, error) {
 		return "", fmt.Errorf("Invalid project config, expected config modules to be non-empty")
 	}
 
-	pConfigModules, err := yaml.Marshal(projectConfig.Modules)
+	pConfigModules, err := yaml.Marshal(withoutSecrets(projectConfig.Modules))
 	if err != nil {
 		return "", err
 	}
 
 	t := struct {
+		APIVersion             string
 		Name                   string
 		ShouldPushRepositories bool
 		Modules                string
 	}{
+		APIVersion:             CurrentAPIVersion,
 		Name:                   projectConfig.Name,
 		ShouldPushRepositories: projectConfig.ShouldPushRepositories,
 		Modules:                util.IndentString(string(pConfigModules), 2),
@@ -77,3 +109,21 @@ func getProjectFileContent(projectConfig ZeroProjectConfig) (string, error) {
 	}
 	return tplBuffer.String(), nil
 }
+
+// withoutSecrets returns a copy of the modules without their secret parameters
+func withoutSecrets(modules Modules) Modules {
+	result := Modules{}
+	for name, mod := range modules {
+		if len(mod.Secrets) > 0 {
+			params := Parameters{}
+			for key, value := range mod.Parameters {
+				if _, isSecret := mod.Secrets[key]; !isSecret {
+					params[key] = value
+				}
+			}
+			mod.Parameters = params
+		}
+		result[name] = mod
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package projectconfig_test
 
 import (
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -28,6 +29,7 @@ func TestCreateProjectConfigFile(t *testing.T) {
 	}
 
 	expectedConfig := &projectconfig.ZeroProjectConfig{
+		APIVersion:             projectconfig.CurrentAPIVersion,
 		Name:                   projectName,
 		ShouldPushRepositories: false,
 		Modules:                eksGoReactSampleModules(),
@@ -40,7 +42,8 @@ func TestCreateProjectConfigFile(t *testing.T) {
 	}
 
 	t.Run("Should return a valid project config", func(t *testing.T) {
-		resultConfig := projectconfig.LoadConfig(path.Join(testDirPath, constants.ZeroProjectYml))
+		resultConfig, err := projectconfig.LoadConfig(path.Join(testDirPath, constants.ZeroProjectYml))
+		assert.NoError(t, err)
 
 		if !cmp.Equal(expectedConfig, resultConfig, cmpopts.EquateEmpty()) {
 			t.Errorf("projectconfig.ZeroProjectConfig.Unmarshal mismatch (-expected +result):\n%s", cmp.Diff(expectedConfig, resultConfig))
@@ -53,3 +56,21 @@ func TestCreateProjectConfigFile(t *testing.T) {
 	})
 
 }
+
+func TestCreateProjectConfigFileWithExistingGitignore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gitignore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	projectName := "test-project"
+	assert.NoError(t, os.MkdirAll(path.Join(dir, projectName), os.ModePerm))
+	gitignorePath := path.Join(dir, projectName, ".gitignore")
+	assert.NoError(t, ioutil.WriteFile(gitignorePath, []byte("node_modules"), 0644))
+
+	config := &projectconfig.ZeroProjectConfig{Name: projectName, Modules: eksGoReactSampleModules()}
+	assert.NoError(t, projectconfig.CreateProjectConfigFile(dir, projectName, config))
+	assert.NoError(t, projectconfig.CreateProjectConfigFile(dir, projectName, config))
+
+	content, err := ioutil.ReadFile(gitignorePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "node_modules\n"+constants.ZeroProjectLocalYml+"\n", string(content), "the local overrides should be appended once")
+}
@@ -0,0 +1,257 @@
+package projectconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// referencePattern matches `${...}` references in parameter values, `$${...}` is an escaped literal
+var referencePattern = regexp.MustCompile(`\$?\$\{([^{}]*)\}`)
+
+// interpolator resolves references between values of a project config,
+// keeping track of the references being resolved to detect cycles
+type interpolator struct {
+	config    *ZeroProjectConfig
+	baseDir   string
+	resolved  map[string]interface{}
+	resolving []string
+	// sensitive are the keys whose values come from outside the project files, with where they came from,
+	// eg: ${env:GITHUB_TOKEN}. Values referencing a sensitive key are sensitive too
+	sensitive map[string]string
+}
+
+// Interpolate resolves the references in the project and module parameter values.
+// The supported references are:
+//
+//	${env:NAME}                           value of the environment variable NAME
+//	${file:PATH}                          content of the file at PATH, relative to baseDir
+//	${project.name}                       name of the project
+//	${parameters.KEY}                     top-level project parameter KEY
+//	${modules.MODULE.parameters.KEY}      parameter KEY of module MODULE
+//
+// A reference can be escaped as $${...} to keep it as a literal.
+// Strings nested in list and map values are interpolated as well. A value which consists of a single reference
+// takes the type of the referenced value, references embedded in text use its string form (see FormatValue).
+func (c *ZeroProjectConfig) Interpolate(baseDir string) error {
+	return c.interpolate(baseDir, map[string]string{})
+}
+
+// interpolate is Interpolate which also adds the keys whose values were resolved from env or file references to sensitive,
+// sensitive is seeded with the keys already known to be sensitive, such as secrets
+func (c *ZeroProjectConfig) interpolate(baseDir string, sensitive map[string]string) error {
+	in := &interpolator{
+		config:    c,
+		baseDir:   baseDir,
+		resolved:  map[string]interface{}{},
+		sensitive: sensitive,
+	}
+
+	for _, key := range sortedKeys(c.Parameters) {
+		if _, err := in.resolveKey(parameterKey(key)); err != nil {
+			return err
+		}
+	}
+	for _, name := range c.moduleNames() {
+		for _, key := range sortedKeys(c.Modules[name].Parameters) {
+			if _, err := in.resolveKey(moduleParameterKey(name, key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key := range c.Parameters {
+		c.Parameters[key] = in.resolved[parameterKey(key)]
+	}
+	for name, mod := range c.Modules {
+		for key := range mod.Parameters {
+			mod.Parameters[key] = in.resolved[moduleParameterKey(name, key)]
+		}
+	}
+	return nil
+}
+
+// resolveKey returns the interpolated value of the parameter identified by key
+func (in *interpolator) resolveKey(key string) (interface{}, error) {
+	if value, ok := in.resolved[key]; ok {
+		return value, nil
+	}
+	for i, k := range in.resolving {
+		if k == key {
+			cycle := append(append([]string{}, in.resolving[i:]...), key)
+			return nil, fmt.Errorf("reference cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	raw, err := in.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	in.resolving = append(in.resolving, key)
+	value, err := in.interpolateValue(raw, key)
+	in.resolving = in.resolving[:len(in.resolving)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	in.resolved[key] = value
+	return value, nil
+}
+
+// lookup returns the raw, uninterpolated value of the parameter identified by key
+func (in *interpolator) lookup(key string) (interface{}, error) {
+	if strings.HasPrefix(key, "parameters.") {
+		name := strings.TrimPrefix(key, "parameters.")
+		value, ok := in.config.Parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("project parameter %q is not defined", name)
+		}
+		return value, nil
+	}
+
+	// modules.<module>.parameters.<key>, module names may contain dots
+	path := strings.TrimPrefix(key, "modules.")
+	separator := strings.LastIndex(path, ".parameters.")
+	if !strings.HasPrefix(key, "modules.") || separator == -1 {
+		return nil, fmt.Errorf("unsupported reference %q", key)
+	}
+	moduleName, name := path[:separator], path[separator+len(".parameters."):]
+	mod, ok := in.config.Modules[moduleName]
+	if !ok {
+		return nil, fmt.Errorf("module %q is not defined in the project", moduleName)
+	}
+	value, ok := mod.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("parameter %q is not defined in module %q", name, moduleName)
+	}
+	return value, nil
+}
+
+// interpolateValue interpolates the strings in value, walking through lists and maps
+func (in *interpolator) interpolateValue(value interface{}, origin string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return in.interpolateString(v, origin)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := in.interpolateValue(item, origin)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := in.interpolateValue(item, origin)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// interpolateString replaces all the references in value, origin is the key the value belongs to
+func (in *interpolator) interpolateString(value string, origin string) (interface{}, error) {
+	if match := referencePattern.FindStringSubmatch(value); match != nil && match[0] == value && !strings.HasPrefix(value, "$$") {
+		resolved, err := in.resolveReference(strings.TrimSpace(match[1]), origin)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve %s in %s: %v", value, origin, err)
+		}
+		return resolved, nil
+	}
+
+	var resolveErr error
+	result := referencePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		reference := strings.TrimSpace(referencePattern.FindStringSubmatch(match)[1])
+		resolved, err := in.resolveReference(reference, origin)
+		if err != nil {
+			resolveErr = fmt.Errorf("unable to resolve %s in %s: %v", match, origin, err)
+		}
+		return FormatValue(resolved)
+	})
+	return result, resolveErr
+}
+
+func (in *interpolator) resolveReference(reference string, origin string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(reference, "env:"), strings.HasPrefix(reference, "file:"):
+		in.markSensitive(origin, fmt.Sprintf("${%s}", reference))
+	case strings.HasPrefix(reference, "parameters."), strings.HasPrefix(reference, "modules."):
+		defer func() {
+			if source, ok := in.sensitive[reference]; ok {
+				in.markSensitive(origin, source)
+			}
+		}()
+	}
+
+	switch {
+	case strings.HasPrefix(reference, "env:"):
+		name := strings.TrimPrefix(reference, "env:")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %q is not set", name)
+		}
+		return value, nil
+
+	case strings.HasPrefix(reference, "file:"):
+		filePath := strings.TrimPrefix(reference, "file:")
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(in.baseDir, filePath)
+		}
+		content, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		// Files usually end with a newline which is not part of the value
+		return strings.TrimRight(string(content), "\r\n"), nil
+
+	case reference == "project.name":
+		return in.config.Name, nil
+
+	case strings.HasPrefix(reference, "parameters."), strings.HasPrefix(reference, "modules."):
+		return in.resolveKey(reference)
+	}
+	return nil, fmt.Errorf("unsupported reference %q", reference)
+}
+
+// markSensitive records where the value of key came from, the first sensitive reference of a value is kept
+func (in *interpolator) markSensitive(key string, source string) {
+	if _, ok := in.sensitive[key]; !ok {
+		in.sensitive[key] = source
+	}
+}
+
+func parameterKey(key string) string {
+	return "parameters." + key
+}
+
+func moduleParameterKey(moduleName string, key string) string {
+	return fmt.Sprintf("modules.%s.parameters.%s", moduleName, key)
+}
+
+// moduleNames returns the names of the modules in a stable order
+func (c *ZeroProjectConfig) moduleNames() []string {
+	names := make([]string, 0, len(c.Modules))
+	for name := range c.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
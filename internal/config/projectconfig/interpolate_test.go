@@ -0,0 +1,104 @@
+package projectconfig_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func interpolationConfig() *projectconfig.ZeroProjectConfig {
+	return &projectconfig.ZeroProjectConfig{
+		Name:       "abc",
+		Parameters: projectconfig.Parameters{"region": "us-west-2"},
+		Modules: projectconfig.Modules{
+			"aws-eks-stack": projectconfig.NewModule(projectconfig.Parameters{
+				"region": "${parameters.region}",
+				"bucket": "${project.name}-assets",
+			}, "infrastructure", "", "", []string{}, []projectconfig.Condition{}),
+			"backend": projectconfig.NewModule(projectconfig.Parameters{
+				"region":  "${modules.aws-eks-stack.parameters.region}",
+				"bucket":  "s3://${modules.aws-eks-stack.parameters.bucket}",
+				"literal": "$${env:NOT_RESOLVED}",
+			}, "backend", "", "", []string{}, []projectconfig.Condition{}),
+		},
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Run("Should resolve project and module references", func(t *testing.T) {
+		config := interpolationConfig()
+		assert.NoError(t, config.Interpolate("."))
+
+		assert.Equal(t, "us-west-2", config.Modules["aws-eks-stack"].Parameters["region"])
+		assert.Equal(t, "abc-assets", config.Modules["aws-eks-stack"].Parameters["bucket"])
+		assert.Equal(t, "us-west-2", config.Modules["backend"].Parameters["region"])
+		assert.Equal(t, "s3://abc-assets", config.Modules["backend"].Parameters["bucket"])
+	})
+
+	t.Run("Should keep escaped references as literals", func(t *testing.T) {
+		config := interpolationConfig()
+		assert.NoError(t, config.Interpolate("."))
+		assert.Equal(t, "${env:NOT_RESOLVED}", config.Modules["backend"].Parameters["literal"])
+	})
+
+	t.Run("Should resolve environment variables", func(t *testing.T) {
+		os.Setenv("ZERO_TEST_TOKEN", "secret-token")
+		defer os.Unsetenv("ZERO_TEST_TOKEN")
+
+		config := interpolationConfig()
+		config.Modules["backend"].Parameters["token"] = "${env:ZERO_TEST_TOKEN}"
+		assert.NoError(t, config.Interpolate("."))
+		assert.Equal(t, "secret-token", config.Modules["backend"].Parameters["token"])
+	})
+
+	t.Run("Should resolve files relative to the config", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "interpolate")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "key.pem"), []byte("KEY CONTENT\n"), 0600))
+
+		config := interpolationConfig()
+		config.Modules["backend"].Parameters["key"] = "${file:./key.pem}"
+		assert.NoError(t, config.Interpolate(dir))
+		assert.Equal(t, "KEY CONTENT", config.Modules["backend"].Parameters["key"])
+	})
+
+	t.Run("Should fail on unresolvable references", func(t *testing.T) {
+		config := interpolationConfig()
+		config.Modules["backend"].Parameters["token"] = "${env:ZERO_TEST_UNDEFINED}"
+		err := config.Interpolate(".")
+		assert.EqualError(t, err, `unable to resolve ${env:ZERO_TEST_UNDEFINED} in modules.backend.parameters.token: environment variable "ZERO_TEST_UNDEFINED" is not set`)
+
+		config = interpolationConfig()
+		config.Modules["backend"].Parameters["other"] = "${modules.frontend.parameters.host}"
+		err = config.Interpolate(".")
+		assert.EqualError(t, err, `unable to resolve ${modules.frontend.parameters.host} in modules.backend.parameters.other: module "frontend" is not defined in the project`)
+	})
+
+	t.Run("Should keep the type of referenced values", func(t *testing.T) {
+		config := interpolationConfig()
+		config.Parameters["subdomains"] = []interface{}{"api", "www"}
+		config.Parameters["replicas"] = 3
+		config.Modules["backend"].Parameters["subdomains"] = "${parameters.subdomains}"
+		config.Modules["backend"].Parameters["hosts"] = []interface{}{"${project.name}.com", "${parameters.replicas}"}
+		config.Modules["backend"].Parameters["description"] = "${parameters.replicas} replicas of ${parameters.subdomains}"
+		assert.NoError(t, config.Interpolate("."))
+
+		assert.Equal(t, []interface{}{"api", "www"}, config.Modules["backend"].Parameters["subdomains"])
+		assert.Equal(t, []interface{}{"abc.com", 3}, config.Modules["backend"].Parameters["hosts"])
+		assert.Equal(t, `3 replicas of ["api","www"]`, config.Modules["backend"].Parameters["description"])
+	})
+
+	t.Run("Should fail on reference cycles", func(t *testing.T) {
+		config := interpolationConfig()
+		config.Modules["backend"].Parameters["a"] = "${modules.backend.parameters.b}"
+		config.Modules["backend"].Parameters["b"] = "${modules.backend.parameters.a}"
+		err := config.Interpolate(".")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "reference cycle detected: modules.backend.parameters.a -> modules.backend.parameters.b -> modules.backend.parameters.a")
+	})
+}
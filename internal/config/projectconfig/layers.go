@@ -0,0 +1,126 @@
+package projectconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/commitdev/zero/internal/constants"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigSources maps the path of each value in a layered project config,
+// eg. `modules.backend.parameters.region`, to the file which supplied it
+type ConfigSources map[string]string
+
+// Source returns the file which supplied the value at path, values inside
+// a list or a map that was supplied as a whole belong to their parent
+func (s ConfigSources) Source(path string) string {
+	for p := path; p != ""; p = parentPath(p) {
+		if source, ok := s[p]; ok {
+			return source
+		}
+	}
+	return ""
+}
+
+// ConfigLayers returns the files making up a project config, in order of precedence.
+// Later files win, the local override file next to the first file is always applied last if it exists.
+func ConfigLayers(filePaths ...string) []string {
+	layers := []string{}
+	for _, filePath := range filePaths {
+		if strings.TrimSpace(filePath) != "" && !containsPath(layers, filePath) {
+			layers = append(layers, filePath)
+		}
+	}
+	if len(layers) == 0 {
+		return layers
+	}
+
+	localPath := filepath.Join(filepath.Dir(layers[0]), constants.ZeroProjectLocalYml)
+	if _, err := os.Stat(localPath); err == nil && !containsPath(layers, localPath) {
+		layers = append(layers, localPath)
+	}
+	return layers
+}
+
+// mergeLayers reads and deep-merges the files, maps are merged key by key while
+// any other value, including lists, replaces the value of the previous files
+func mergeLayers(filePaths []string) (map[interface{}]interface{}, ConfigSources, error) {
+	merged := map[interface{}]interface{}{}
+	sources := ConfigSources{}
+
+	for _, filePath := range filePaths {
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config: %v", err)
+		}
+		data, _, err = Migrations.UpgradeBytes(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load config %s: %v", filePath, err)
+		}
+		layer := map[interface{}]interface{}{}
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config %s: %v", filePath, err)
+		}
+		mergeMap(merged, layer, "", filePath, sources)
+	}
+	return merged, sources, nil
+}
+
+func mergeMap(dst map[interface{}]interface{}, src map[interface{}]interface{}, path string, source string, sources ConfigSources) {
+	for key, value := range src {
+		valuePath := joinPath(path, fmt.Sprintf("%v", key))
+		srcMap, srcIsMap := value.(map[interface{}]interface{})
+		dstMap, dstIsMap := dst[key].(map[interface{}]interface{})
+
+		if srcIsMap && dstIsMap {
+			mergeMap(dstMap, srcMap, valuePath, source, sources)
+			continue
+		}
+
+		sources.removeUnder(valuePath)
+		if srcIsMap && len(srcMap) > 0 {
+			dstMap = map[interface{}]interface{}{}
+			dst[key] = dstMap
+			mergeMap(dstMap, srcMap, valuePath, source, sources)
+		} else {
+			dst[key] = value
+			sources[valuePath] = source
+		}
+	}
+}
+
+// removeUnder forgets the sources of path and all of its children
+func (s ConfigSources) removeUnder(path string) {
+	for p := range s {
+		if p == path || strings.HasPrefix(p, path+".") {
+			delete(s, p)
+		}
+	}
+}
+
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "."); i != -1 {
+		return path[:i]
+	}
+	return ""
+}
+
+func containsPath(paths []string, target string) bool {
+	for _, p := range paths {
+		if filepath.Clean(p) == filepath.Clean(target) {
+			return true
+		}
+	}
+	return false
+}
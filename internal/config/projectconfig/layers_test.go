@@ -0,0 +1,96 @@
+package projectconfig_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+const layeredTestDir = "../../../tests/test_data/layered/"
+
+func TestLayeredConfig(t *testing.T) {
+	mainFile := filepath.Join(layeredTestDir, constants.ZeroProjectYml)
+	localFile := filepath.Join(layeredTestDir, constants.ZeroProjectLocalYml)
+	overrideFile := filepath.Join(layeredTestDir, "override.yml")
+
+	t.Run("Local overrides are applied last", func(t *testing.T) {
+		assert.Equal(t, []string{mainFile, overrideFile, localFile}, projectconfig.ConfigLayers(mainFile, overrideFile))
+	})
+
+	t.Run("Should merge the local override file", func(t *testing.T) {
+		config, err := projectconfig.LoadConfig(mainFile)
+		assert.NoError(t, err)
+
+		backend := config.Modules["backend"]
+		assert.Equal(t, "my-profile", backend.Parameters["profile"])
+		assert.Equal(t, "us-west-2", backend.Parameters["region"], "values missing from the override are kept")
+		assert.Equal(t, "../zero-deployable-backend", backend.Files.Source)
+		assert.Equal(t, "github.com/org/backend", backend.Files.Repository)
+		assert.Equal(t, true, config.ShouldPushRepositories)
+	})
+
+	t.Run("Later config files win", func(t *testing.T) {
+		config, sources, err := projectconfig.LoadLayeredConfig(true, mainFile, overrideFile)
+		assert.NoError(t, err)
+
+		backend := config.Modules["backend"]
+		assert.Equal(t, "ca-central-1", backend.Parameters["region"])
+		assert.Equal(t, "my-profile", backend.Parameters["profile"])
+		assert.Equal(t, false, config.ShouldPushRepositories)
+		assert.Equal(t, "us-west-2", config.Modules["frontend"].Parameters["region"])
+
+		assert.Equal(t, overrideFile, sources.Source("modules.backend.parameters.region"))
+		assert.Equal(t, localFile, sources.Source("modules.backend.parameters.profile"))
+		assert.Equal(t, mainFile, sources.Source("modules.frontend.files.repo"))
+	})
+
+	t.Run("Should render the merged config with sources", func(t *testing.T) {
+		config, sources, err := projectconfig.LoadLayeredConfig(true, mainFile, overrideFile)
+		assert.NoError(t, err)
+
+		content, err := projectconfig.RenderWithSources(config, sources)
+		assert.NoError(t, err)
+		assert.Contains(t, content, "  backend:\n    parameters:\n      profile: my-profile  # "+localFile+"\n      region: ca-central-1  # "+overrideFile+"\n")
+		assert.Contains(t, content, "shouldPushRepositories: false  # "+overrideFile+"\n")
+	})
+}
+
+func TestRenderWithSourcesMasksReferencedValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "masked")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "token.txt"), []byte("file-token\n"), 0644))
+	os.Setenv("ZERO_TEST_MASKED_TOKEN", "env-token")
+	defer os.Unsetenv("ZERO_TEST_MASKED_TOKEN")
+
+	mainFile := filepath.Join(dir, constants.ZeroProjectYml)
+	assert.NoError(t, ioutil.WriteFile(mainFile, []byte(`
+name: abc
+parameters:
+  apiToken: ${env:ZERO_TEST_MASKED_TOKEN}
+modules:
+  backend:
+    parameters:
+      deployKey: ${file:token.txt}
+      header: Bearer ${parameters.apiToken}
+      region: us-west-2
+    files:
+      source: ../zero-deployable-backend
+`), 0644))
+
+	config, sources, err := projectconfig.LoadLayeredConfig(true, mainFile)
+	assert.NoError(t, err)
+	content, err := projectconfig.RenderWithSources(config, sources)
+	assert.NoError(t, err)
+
+	assert.Contains(t, content, "  apiToken: <secret>  # ${env:ZERO_TEST_MASKED_TOKEN}\n")
+	assert.Contains(t, content, "      deployKey: <secret>  # ${file:token.txt}\n")
+	assert.Contains(t, content, "      header: <secret>  # ${env:ZERO_TEST_MASKED_TOKEN}\n", "values referencing sensitive values are masked too")
+	assert.Contains(t, content, "      region: us-west-2  # "+mainFile+"\n")
+	assert.NotContains(t, content, "token\n")
+}
@@ -0,0 +1,27 @@
+package projectconfig
+
+import (
+	"github.com/commitdev/zero/internal/config/migration"
+)
+
+// CurrentAPIVersion is the latest version of the zero-project.yml format supported by this version of Zero
+const CurrentAPIVersion = "v1"
+
+// Migrations upgrade project files written by older versions of Zero,
+// add a migration here whenever the format of zero-project.yml changes
+var Migrations = migration.Registry{
+	Kind:    "project",
+	Current: CurrentAPIVersion,
+	Migrations: []migration.Migration{
+		{
+			From:        migration.LegacyVersion,
+			To:          "v1",
+			Description: "Add apiVersion to unversioned project files",
+		},
+	},
+}
+
+// MigrateFile upgrades the project file at filePath in place, see migration.Registry.MigrateFile
+func MigrateFile(filePath string) ([]migration.Migration, string, error) {
+	return Migrations.MigrateFile(filePath)
+}
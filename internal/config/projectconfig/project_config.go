@@ -2,9 +2,14 @@ package projectconfig
 
 import (
 	"errors"
-	"io/ioutil"
-	"log"
-
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/schema"
+	"github.com/commitdev/zero/internal/constants"
 	"github.com/commitdev/zero/pkg/util/flog"
 	"github.com/hashicorp/terraform/dag"
 	"github.com/k0kubun/pp"
@@ -14,11 +19,21 @@ import (
 // GraphRootName represents the root of the graph of modules in a project
 const GraphRootName = "graphRoot"
 
+var projectSchema = schema.Generate(constants.ZeroProjectYml, reflect.TypeOf(ZeroProjectConfig{}))
+
+// Schema returns the JSON Schema of zero-project.yml, it is used to validate the config when it is loaded
+func Schema() *schema.Schema {
+	return projectSchema
+}
+
 type ZeroProjectConfig struct {
-	Name                   string `yaml:"name"`
-	ShouldPushRepositories bool   `yaml:"shouldPushRepositories"`
-	Parameters             map[string]string
-	Modules                Modules `yaml:"modules"`
+	APIVersion             string     `yaml:"apiVersion,omitempty"`
+	Name                   string     `yaml:"name"`
+	ShouldPushRepositories bool       `yaml:"shouldPushRepositories"`
+	Parameters             Parameters `yaml:"parameters,omitempty"`
+	// Environments are the names of the environments the project is deployed to, eg: staging, production
+	Environments []string `yaml:"environments,omitempty"`
+	Modules      Modules  `yaml:"modules"`
 }
 
 type Modules map[string]Module
@@ -28,53 +43,160 @@ type Module struct {
 	Parameters Parameters `yaml:"parameters,omitempty"`
 	Files      Files
 	Conditions []Condition `yaml:"conditions,omitempty"`
+	// Secrets are the parameters stored in the encrypted secrets file instead of the project file,
+	// they are also merged into Parameters when the project is loaded
+	Secrets Parameters `yaml:"-"`
 }
 
-// ReadVendorCredentialsFromModule uses parsed project-config's module
-// based on vendor parameter, retrieve the vendor's credential
-// for pre-defined functionalities (eg: Github api key for pushing repos to github)
-func ReadVendorCredentialsFromModule(m Module, vendor string) (error, string) {
-	// this mapping could be useful for module config as well
-	vendorToParamMap := map[string]string{
-		"github":   "githubAccessToken",
-		"circleci": "circleciApiKey",
-	}
-	if parameterKey, ok := vendorToParamMap[vendor]; ok {
-		if val, ok := m.Parameters[parameterKey]; ok {
-			return nil, val
-		}
-		return errors.New("Parameter not found in module."), ""
-	}
-	return errors.New("Unsupported vendor provided."), ""
-}
+// Actions of module conditions, applied when the module is rendered
+const (
+	// ActionIgnoreFile excludes the paths in data from the rendered module
+	ActionIgnoreFile = "ignoreFile"
+	// ActionIncludeOnly excludes all the paths but the ones in data from the rendered module
+	ActionIncludeOnly = "includeOnly"
+	// ActionRenameFile renders the path data[0] as data[1]
+	ActionRenameFile = "renameFile"
+	// ActionAppendToFile appends the lines data[1:] to the rendered file data[0]
+	ActionAppendToFile = "appendToFile"
+	// ActionReplaceInFile replaces data[1] with data[2] in the rendered file data[0]
+	ActionReplaceInFile = "replaceInFile"
+)
 
-type Parameters map[string]string
+// ConditionActions are the actions supported by module conditions
+var ConditionActions = []string{ActionIgnoreFile, ActionIncludeOnly, ActionRenameFile, ActionAppendToFile, ActionReplaceInFile}
 
 type Condition struct {
-	Action     string   `yaml:"action"`
-	MatchField string   `yaml:"matchField"`
-	WhenValue  string   `yaml:"whenValue"`
+	Action     string `yaml:"action"`
+	Expression `yaml:",inline"`
 	Data       []string `yaml:"data,omitempty"`
 }
 
+// Validate checks the condition has a supported action, the data its action needs and a well formed expression
+func (c Condition) Validate() error {
+	found := false
+	for _, action := range ConditionActions {
+		found = found || action == c.Action
+	}
+	if !found {
+		return fmt.Errorf("unsupported action %q, expected one of %s", c.Action, strings.Join(ConditionActions, ", "))
+	}
+	if err := CheckConditionData(c.Action, c.Data); err != nil {
+		return err
+	}
+	return c.Expression.Validate()
+}
+
+// CheckConditionData makes sure the data of a condition has the arguments its action needs
+func CheckConditionData(action string, data []string) error {
+	switch action {
+	case ActionIgnoreFile, ActionIncludeOnly:
+		if len(data) == 0 {
+			return fmt.Errorf("%s requires a list of paths as data", action)
+		}
+		for _, pattern := range data {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid path pattern %q: %v", pattern, err)
+			}
+		}
+	case ActionRenameFile:
+		if len(data) != 2 {
+			return fmt.Errorf("%s requires data: [<path>, <new path>]", action)
+		}
+	case ActionAppendToFile:
+		if len(data) < 2 {
+			return fmt.Errorf("%s requires data: [<path>, <line>...]", action)
+		}
+	case ActionReplaceInFile:
+		if len(data) != 3 {
+			return fmt.Errorf("%s requires data: [<path>, <old>, <new>]", action)
+		}
+	}
+	return nil
+}
+
+// validateConditions checks the conditions of the modules, they are copied from the modules but can be edited in the project file
+func (c *ZeroProjectConfig) validateConditions() error {
+	for _, name := range c.moduleNames() {
+		for i, condition := range c.Modules[name].Conditions {
+			if err := condition.Validate(); err != nil {
+				return fmt.Errorf("modules.%s.conditions[%d]: %v", name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
 type Files struct {
 	Directory  string `yaml:"dir,omitempty"`
 	Repository string `yaml:"repo,omitempty"`
 	Source     string
 }
 
-func LoadConfig(filePath string) *ZeroProjectConfig {
-	config := &ZeroProjectConfig{}
-	data, err := ioutil.ReadFile(filePath)
+// LoadConfig reads, merges and parses the project config files, then resolves the references
+// in its parameter values so they are ready to be used by templates and commands.
+// See ConfigLayers for the precedence of the files.
+func LoadConfig(filePaths ...string) (*ZeroProjectConfig, error) {
+	config, _, err := LoadLayeredConfig(true, filePaths...)
+	return config, err
+}
+
+// LoadLayeredConfig is LoadConfig which also returns the file each value came from.
+// When resolve is false, the secrets and references are left untouched.
+func LoadLayeredConfig(resolve bool, filePaths ...string) (*ZeroProjectConfig, ConfigSources, error) {
+	layers := ConfigLayers(filePaths...)
+	if len(layers) == 0 {
+		return nil, nil, errors.New("no config file provided")
+	}
+	mainFile := layers[0]
+
+	merged, sources, err := mergeLayers(layers)
+	if err != nil {
+		return nil, nil, err
+	}
+	// unknown fields are only warned about, the project may have been written by a newer version of Zero
+	errs, unknown := Schema().ValidateLenient(merged)
+	for _, field := range unknown {
+		flog.Warnf("%s: %s", strings.Join(layers, ", "), field)
+	}
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid project config %s:%v", strings.Join(layers, ", "), errs)
+	}
+	data, err := yaml.Marshal(merged)
 	if err != nil {
-		log.Panicf("failed to read config: %v", err)
+		return nil, nil, err
 	}
+	config := &ZeroProjectConfig{}
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
-		log.Panicf("failed to parse config: %v", err)
+		return nil, nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+	if err := config.validateConditions(); err != nil {
+		return nil, nil, fmt.Errorf("invalid project config %s:\n\t%v", strings.Join(layers, ", "), err)
+	}
+	if !resolve {
+		return config, sources, nil
+	}
+
+	err = config.loadSecrets(mainFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load secrets: %v", err)
+	}
+	sensitive := map[string]string{}
+	for name, mod := range config.Modules {
+		for key := range mod.Secrets {
+			sensitive[moduleParameterKey(name, key)] = SecretsFilePath(mainFile)
+		}
+	}
+	err = config.interpolate(filepath.Dir(mainFile), sensitive)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve config %s: %v", mainFile, err)
+	}
+	// values resolved from secrets, env or file references are attributed to them so they can be masked
+	for key, source := range sensitive {
+		sources[key] = source
 	}
-	flog.Debugf("Loaded project config: %s from %s", config.Name, filePath)
-	return config
+	flog.Debugf("Loaded project config: %s from %s", config.Name, strings.Join(layers, ", "))
+	return config, sources, nil
 }
 
 func (c *ZeroProjectConfig) Print() {
@@ -24,19 +24,84 @@ func TestLoadConfig(t *testing.T) {
 	filePath := file.Name()
 
 	want := &projectconfig.ZeroProjectConfig{
+		APIVersion:             projectconfig.CurrentAPIVersion,
 		Name:                   "abc",
 		ShouldPushRepositories: true,
+		Environments:           []string{"staging", "production"},
 		Modules:                eksGoReactSampleModules(),
 	}
 
 	t.Run("Should load and unmarshal config correctly", func(t *testing.T) {
-		got := projectconfig.LoadConfig(filePath)
+		got, err := projectconfig.LoadConfig(filePath)
+		assert.NoError(t, err)
 		if !cmp.Equal(want, got, cmpopts.EquateEmpty()) {
 			t.Errorf("projectconfig.ZeroProjectConfig.Unmarshal mismatch (-want +got):\n%s", cmp.Diff(want, got))
 		}
 	})
 }
 
+func TestLoadConfigWithInvalidCondition(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conditions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	content := `name: abc
+modules:
+  backend:
+    files:
+      source: ../backend
+    conditions:
+      - action: renameFile
+        matchField: language
+        whenValue: go
+        data:
+          - Dockerfile.go
+`
+	filePath := filepath.Join(dir, constants.ZeroProjectYml)
+	assert.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+
+	_, err = projectconfig.LoadConfig(filePath)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "modules.backend.conditions[0]: renameFile requires data: [<path>, <new path>]")
+}
+
+func TestLoadConfigWithTypedParameters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "typed")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, constants.ZeroProjectYml)
+	assert.NoError(t, ioutil.WriteFile(filePath, []byte(`
+name: abc
+modules:
+  backend:
+    parameters:
+      enabled: true
+      replicas: 3
+      subdomains: [api, www]
+      labels:
+        team: platform
+    files:
+      dir: backend
+      source: github.com/commitdev/zero-deployable-backend
+`), 0644))
+
+	config, err := projectconfig.LoadConfig(filePath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	params := config.Modules["backend"].Parameters
+	assert.Equal(t, true, params["enabled"])
+	assert.Equal(t, 3, params["replicas"])
+	assert.Equal(t, []interface{}{"api", "www"}, params["subdomains"])
+	assert.Equal(t, map[string]interface{}{"team": "platform"}, params["labels"])
+
+	assert.Equal(t, map[string]string{
+		"enabled":    "true",
+		"replicas":   "3",
+		"subdomains": `["api","www"]`,
+		"labels":     `{"team":"platform"}`,
+	}, params.Strings())
+}
+
 func eksGoReactSampleModules() projectconfig.Modules {
 	parameters := projectconfig.Parameters{"a": "b"}
 	return projectconfig.Modules{
@@ -53,6 +118,10 @@ name: abc
 
 shouldPushRepositories: true
 
+environments:
+  - staging
+  - production
+
 modules:
   aws-eks-stack:
     parameters:
@@ -82,7 +151,8 @@ func TestProjectConfigModuleGraph(t *testing.T) {
 	configPath := filepath.Join("../../../tests/test_data/projectconfig/", constants.ZeroProjectYml)
 
 	t.Run("Should generate a valid, correct graph based on the project config", func(t *testing.T) {
-		pc := projectconfig.LoadConfig(configPath)
+		pc, err := projectconfig.LoadConfig(configPath)
+		assert.NoError(t, err)
 		graph := pc.GetDAG()
 
 		// Validate the graph
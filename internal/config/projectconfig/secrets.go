@@ -0,0 +1,95 @@
+package projectconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/secrets"
+	"github.com/commitdev/zero/pkg/util/flog"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SecretValues is the decrypted content of the project's secrets file
+type SecretValues struct {
+	Modules map[string]Parameters `yaml:"modules"`
+}
+
+// SecretsFilePath returns the path of the secrets file belonging to the project config at configPath
+func SecretsFilePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), constants.ZeroSecretsFile)
+}
+
+// loadSecrets decrypts the secrets file next to the project config if there is one,
+// and merges the secret values into their module's parameters
+func (c *ZeroProjectConfig) loadSecrets(configPath string) error {
+	secretsPath := SecretsFilePath(configPath)
+	if _, err := os.Stat(secretsPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	plaintext, _, err := secrets.ReadFile(secretsPath)
+	if err != nil {
+		return err
+	}
+	values, err := ParseSecretValues(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", secretsPath, err)
+	}
+
+	for moduleName, moduleSecrets := range values.Modules {
+		mod, ok := c.Modules[moduleName]
+		if !ok {
+			flog.Warnf("%s contains secrets for module %s which is not part of the project", secretsPath, moduleName)
+			continue
+		}
+		if mod.Parameters == nil {
+			mod.Parameters = Parameters{}
+		}
+		for key, value := range moduleSecrets {
+			mod.Parameters[key] = value
+		}
+		mod.Secrets = moduleSecrets
+		c.Modules[moduleName] = mod
+	}
+	flog.Debugf("Loaded secrets from %s", secretsPath)
+	return nil
+}
+
+// writeSecrets encrypts the secret parameters of all modules into the secrets file in dir.
+// An existing secrets file keeps its key, otherwise a new one is created.
+func (c *ZeroProjectConfig) writeSecrets(dir string) error {
+	values := SecretValues{Modules: map[string]Parameters{}}
+	for moduleName, mod := range c.Modules {
+		if len(mod.Secrets) > 0 {
+			values.Modules[moduleName] = mod.Secrets
+		}
+	}
+	if len(values.Modules) == 0 {
+		return nil
+	}
+
+	plaintext, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	secretsPath := filepath.Join(dir, constants.ZeroSecretsFile)
+	_, key, err := secrets.ReadFile(secretsPath)
+	if os.IsNotExist(err) {
+		key, err = secrets.NewKey()
+	}
+	if err != nil {
+		return err
+	}
+	flog.Debugf("Writing secrets to %s using %s", secretsPath, key.Description())
+	return secrets.WriteFile(secretsPath, plaintext, key)
+}
+
+// ParseSecretValues parses the decrypted content of a secrets file
+func ParseSecretValues(plaintext []byte) (SecretValues, error) {
+	values := SecretValues{}
+	err := yaml.UnmarshalStrict(plaintext, &values)
+	return values, err
+}
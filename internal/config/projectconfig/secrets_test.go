@@ -0,0 +1,61 @@
+package projectconfig_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "project-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	originalKeyDir := secrets.KeyDir
+	secrets.KeyDir = filepath.Join(dir, "keys")
+	defer func() { secrets.KeyDir = originalKeyDir }()
+
+	projectName := "secret-project"
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, projectName), os.ModePerm))
+
+	backend := projectconfig.NewModule(projectconfig.Parameters{"region": "us-west-2"}, "backend", "github.com/org/backend", "github.com/commitdev/zero-deployable-backend", []string{}, []projectconfig.Condition{})
+	backend.Secrets = projectconfig.Parameters{"githubAccessToken": "super-secret-token"}
+	config := &projectconfig.ZeroProjectConfig{
+		Name:    projectName,
+		Modules: projectconfig.Modules{"backend": backend},
+	}
+	assert.NoError(t, projectconfig.CreateProjectConfigFile(dir, projectName, config))
+
+	configPath := filepath.Join(dir, projectName, constants.ZeroProjectYml)
+
+	t.Run("Secrets are not written to the project file", func(t *testing.T) {
+		content, err := ioutil.ReadFile(configPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "super-secret-token")
+		assert.NotContains(t, string(content), "githubAccessToken")
+
+		encrypted, err := ioutil.ReadFile(filepath.Join(dir, projectName, constants.ZeroSecretsFile))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(encrypted), "super-secret-token")
+	})
+
+	t.Run("Secrets are merged into the module parameters when loading", func(t *testing.T) {
+		loaded, err := projectconfig.LoadConfig(configPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "super-secret-token", loaded.Modules["backend"].Parameters["githubAccessToken"])
+		assert.Equal(t, "us-west-2", loaded.Modules["backend"].Parameters["region"])
+
+		err, token := moduleconfig.ReadVendorCredentialsFromModule(loaded.Modules["backend"], "github")
+		assert.NoError(t, err)
+		assert.Equal(t, "super-secret-token", token)
+	})
+}
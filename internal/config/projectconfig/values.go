@@ -0,0 +1,86 @@
+package projectconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Parameters are the values of a project or module, keyed by field.
+// Values keep their yaml type: string, bool, int, []interface{} or map[string]interface{}.
+type Parameters map[string]interface{}
+
+// UnmarshalYAML parses the parameters keeping their native yaml types,
+// nested maps are converted to map[string]interface{} so they can be used by templates and encoded as json
+func (p *Parameters) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	values := map[string]interface{}{}
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+	*p = Parameters{}
+	for key, value := range values {
+		(*p)[key] = NormalizeValue(value)
+	}
+	return nil
+}
+
+// Strings returns the parameters in their string form, as exposed to commands through env vars
+func (p Parameters) Strings() map[string]string {
+	result := make(map[string]string, len(p))
+	for key, value := range p {
+		result[key] = FormatValue(value)
+	}
+	return result
+}
+
+// NormalizeValue converts the map[interface{}]interface{} produced by the yaml decoder into map[string]interface{}
+func NormalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprintf("%v", key)] = NormalizeValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = NormalizeValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = NormalizeValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+// FormatValue returns the string form of a parameter value:
+// scalars are formatted as is, lists and maps are encoded as json
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}, map[string]interface{}, []string, map[string]string:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func sortedKeys(params Parameters) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
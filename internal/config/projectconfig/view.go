@@ -0,0 +1,79 @@
+package projectconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/commitdev/zero/internal/constants"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const maskedSecret = "<secret>"
+
+// RenderWithSources renders the config as yaml, annotating each value with the file which supplied it.
+// Values coming from the secrets file or resolved from env or file references are masked.
+func RenderWithSources(config *ZeroProjectConfig, sources ConfigSources) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := renderMapSlice(&b, doc, "", 0, sources); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func renderMapSlice(b *strings.Builder, items yaml.MapSlice, path string, indent int, sources ConfigSources) error {
+	padding := strings.Repeat("  ", indent)
+	for _, item := range items {
+		key := fmt.Sprintf("%v", item.Key)
+		valuePath := joinPath(path, key)
+		source := sources.Source(valuePath)
+
+		if child, ok := item.Value.(yaml.MapSlice); ok && len(child) > 0 {
+			fmt.Fprintf(b, "%s%s:\n", padding, key)
+			if err := renderMapSlice(b, child, valuePath, indent+1, sources); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value := item.Value
+		if isSensitiveSource(source) {
+			value = maskedSecret
+		}
+		rendered, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		annotation := ""
+		if source != "" {
+			annotation = fmt.Sprintf("  # %s", source)
+		}
+
+		lines := strings.Split(strings.TrimRight(string(rendered), "\n"), "\n")
+		if len(lines) == 1 && !strings.HasPrefix(lines[0], "- ") {
+			fmt.Fprintf(b, "%s%s: %s%s\n", padding, key, lines[0], annotation)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s:%s\n", padding, key, annotation)
+		for _, line := range lines {
+			fmt.Fprintf(b, "%s  %s\n", padding, line)
+		}
+	}
+	return nil
+}
+
+// isSensitiveSource returns whether a value from source must be masked: secrets and values of env or file references
+func isSensitiveSource(source string) bool {
+	return filepath.Base(source) == constants.ZeroSecretsFile ||
+		strings.HasPrefix(source, "${env:") ||
+		strings.HasPrefix(source, "${file:")
+}
@@ -0,0 +1,159 @@
+// Package schema generates JSON Schemas from the config structs and their yaml tags,
+// and validates yaml documents against them.
+//
+// The same schema is published with `zero schema` so editors can autocomplete
+// and validate config files, and used by the loaders to report errors with
+// the path of the offending value.
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Draft is the JSON Schema version of the generated schemas
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is the subset of JSON Schema needed to describe the config files
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Describer is implemented by types which don't map directly to a JSON type,
+// eg. a struct unmarshaled from a string
+type Describer interface {
+	JSONSchema() *Schema
+}
+
+var (
+	describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+	mapSliceType  = reflect.TypeOf(yaml.MapSlice{})
+)
+
+// Generate returns the schema for values of type t as they are written in yaml.
+// Recursive structs are described once in the definitions and referenced with $ref.
+func Generate(title string, t reflect.Type) *Schema {
+	g := &generator{visiting: map[reflect.Type]bool{}, recursive: map[reflect.Type]bool{}, definitions: map[string]*Schema{}}
+	s := g.generate(t)
+	s.Schema = Draft
+	s.Title = title
+	if len(g.definitions) > 0 {
+		s.Definitions = g.definitions
+	}
+	return s
+}
+
+type generator struct {
+	visiting    map[reflect.Type]bool
+	recursive   map[reflect.Type]bool
+	definitions map[string]*Schema
+}
+
+func definitionRef(t reflect.Type) *Schema {
+	return &Schema{Ref: "#/definitions/" + t.Name()}
+}
+
+func (g *generator) generate(t reflect.Type) *Schema {
+	if t.Implements(describerType) {
+		return reflect.Zero(t).Interface().(Describer).JSONSchema()
+	}
+	if t == mapSliceType {
+		return &Schema{Type: nullable("object"), AdditionalProperties: &Schema{Type: "string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Ptr:
+		s := g.generate(t.Elem())
+		if typeName, ok := s.Type.(string); ok {
+			s.Type = nullable(typeName)
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: nullable("array"), Items: g.generate(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: nullable("object"), AdditionalProperties: g.generate(t.Elem())}
+	case reflect.Struct:
+		if _, ok := g.definitions[t.Name()]; ok || g.visiting[t] {
+			g.recursive[t] = true
+			return definitionRef(t)
+		}
+		g.visiting[t] = true
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		g.addStructFields(s, t)
+		sort.Strings(s.Required)
+		delete(g.visiting, t)
+		if g.recursive[t] {
+			g.definitions[t.Name()] = s
+			return definitionRef(t)
+		}
+		return s
+	}
+	// interface{} and anything else accepts any value
+	return &Schema{}
+}
+
+// addStructFields adds the fields of t to the object schema s, following the yaml.v2 conventions:
+// the key is the tag name or the lowercased field name, inline fields are flattened into their parent
+// and string fields without omitempty are required.
+func (g *generator) addStructFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, options := parseTag(field.Tag.Get("yaml"))
+		if name == "-" {
+			continue
+		}
+		if options["inline"] {
+			g.addStructFields(s, field.Type)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		property := g.generate(field.Type)
+		s.Properties[name] = property
+		if field.Type.Kind() == reflect.String && !options["omitempty"] {
+			minLength := 1
+			property.MinLength = &minLength
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func parseTag(tag string) (string, map[string]bool) {
+	parts := strings.Split(tag, ",")
+	options := map[string]bool{}
+	for _, option := range parts[1:] {
+		options[option] = true
+	}
+	return parts[0], options
+}
+
+func nullable(typeName string) []string {
+	return []string{typeName, "null"}
+}
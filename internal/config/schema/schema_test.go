@@ -0,0 +1,85 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/commitdev/zero/internal/config/schema"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type inner struct {
+	Value string `yaml:"value,omitempty"`
+}
+
+type testConfig struct {
+	Name     string
+	Count    int               `yaml:"count,omitempty"`
+	Tags     []string          `yaml:"tags,omitempty"`
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	Internal string            `yaml:"-"`
+	inner    `yaml:",inline"`
+}
+
+func validate(t *testing.T, content string) schema.ValidationErrors {
+	var doc interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(content), &doc))
+	return schema.Generate("test", reflect.TypeOf(testConfig{})).Validate(doc)
+}
+
+func TestGenerate(t *testing.T) {
+	s := schema.Generate("test", reflect.TypeOf(testConfig{}))
+
+	assert.Equal(t, schema.Draft, s.Schema)
+	assert.Equal(t, []string{"name"}, s.Required)
+	assert.Contains(t, s.Properties, "value")
+	assert.NotContains(t, s.Properties, "internal")
+	assert.Equal(t, "string", s.Properties["tags"].Items.Type)
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Should accept valid documents", func(t *testing.T) {
+		assert.Len(t, validate(t, "name: foo\ncount: 2\ntags: [a, b]\nlabels:\n  a: b\nvalue: c\n"), 0)
+	})
+
+	t.Run("Should report missing required fields", func(t *testing.T) {
+		errs := validate(t, "count: 2\n")
+		assert.Equal(t, schema.ValidationErrors{{Path: "name", Message: "is required"}}, errs)
+	})
+
+	t.Run("Should report unknown fields and wrong types with their path", func(t *testing.T) {
+		errs := validate(t, "name: foo\nnmae: foo\ncount: many\ntags:\n  - a: b\n")
+		assert.Equal(t, schema.ValidationErrors{
+			{Path: "count", Message: "expected integer but got string"},
+			{Path: "", Message: `unknown field "nmae"`},
+			{Path: "tags[0]", Message: "expected string but got object"},
+		}, errs)
+	})
+
+	t.Run("Should return unknown fields apart when lenient", func(t *testing.T) {
+		var doc interface{}
+		assert.NoError(t, yaml.Unmarshal([]byte("name: foo\nnmae: foo\ncount: many\n"), &doc))
+		errs, unknown := schema.Generate("test", reflect.TypeOf(testConfig{})).ValidateLenient(doc)
+		assert.Equal(t, schema.ValidationErrors{{Path: "count", Message: "expected integer but got string"}}, errs)
+		assert.Equal(t, schema.ValidationErrors{{Path: "", Message: `unknown field "nmae"`}}, unknown)
+	})
+}
+
+type node struct {
+	Name     string `yaml:"name"`
+	Children []node `yaml:"children,omitempty"`
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	s := schema.Generate("tree", reflect.TypeOf(node{}))
+	assert.Equal(t, "#/definitions/node", s.Ref)
+	assert.Contains(t, s.Definitions, "node")
+
+	var doc interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte("name: root\nchildren:\n  - name: a\n    children:\n      - nmae: b\n"), &doc))
+	assert.Equal(t, schema.ValidationErrors{
+		{Path: "children[0].children[0].name", Message: "is required"},
+		{Path: "children[0].children[0]", Message: `unknown field "nmae"`},
+	}, s.Validate(doc))
+}
@@ -0,0 +1,209 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError is a violation of the schema by the value at Path
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is the list of all the violations found in a document
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = "\t" + e.Error()
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// validation collects the violations found in a document, unknown fields are also kept apart
+// so they can be tolerated when loading files written for other versions of Zero
+type validation struct {
+	errs    ValidationErrors
+	unknown ValidationErrors
+}
+
+func (v *validation) add(err ValidationError) {
+	v.errs = append(v.errs, err)
+}
+
+// Validate checks a document, as unmarshaled by yaml into an interface{}, against the schema
+func (s *Schema) Validate(doc interface{}) ValidationErrors {
+	v := &validation{errs: ValidationErrors{}}
+	s.validate(s, doc, "", v)
+	return v.errs
+}
+
+// ValidateLenient is Validate which returns the unknown fields apart from the other violations,
+// loaders warn about unknown fields instead of failing so older versions of Zero can read newer files
+func (s *Schema) ValidateLenient(doc interface{}) (ValidationErrors, ValidationErrors) {
+	v := &validation{errs: ValidationErrors{}, unknown: ValidationErrors{}}
+	s.validate(s, doc, "", v)
+	errs := ValidationErrors{}
+	for _, err := range v.errs {
+		if !v.unknown.contains(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errs, v.unknown
+}
+
+func (errs ValidationErrors) contains(target ValidationError) bool {
+	for _, err := range errs {
+		if err == target {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks value against s, root holds the definitions references are resolved from
+func (s *Schema) validate(root *Schema, value interface{}, path string, v *validation) {
+	if s.Ref != "" {
+		definition, ok := root.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+		if !ok {
+			v.add(ValidationError{path, fmt.Sprintf("unknown schema reference %s", s.Ref)})
+			return
+		}
+		s = definition
+	}
+	if !s.allowsType(typeOf(value)) {
+		v.add(ValidationError{path, fmt.Sprintf("expected %s but got %s", s.typeNames(), typeOf(value))})
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.allowsValue(value) {
+		v.add(ValidationError{path, fmt.Sprintf("must be one of %v", s.Enum)})
+	}
+	if str, ok := value.(string); ok && s.MinLength != nil && len(str) < *s.MinLength {
+		v.add(ValidationError{path, "is required"})
+	}
+
+	switch typed := value.(type) {
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range typed {
+				s.Items.validate(root, item, fmt.Sprintf("%s[%d]", path, i), v)
+			}
+		}
+	case map[interface{}]interface{}:
+		s.validateObject(root, typed, path, v)
+	}
+}
+
+func (s *Schema) validateObject(root *Schema, object map[interface{}]interface{}, path string, v *validation) {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			v.add(ValidationError{joinPath(path, name), "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	values := map[string]interface{}{}
+	for key, value := range object {
+		name := fmt.Sprintf("%v", key)
+		keys = append(keys, name)
+		values[name] = value
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		valuePath := joinPath(path, name)
+		if property, ok := s.Properties[name]; ok {
+			property.validate(root, values[name], valuePath, v)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case *Schema:
+			additional.validate(root, values[name], valuePath, v)
+		case bool:
+			if !additional {
+				err := ValidationError{path, fmt.Sprintf("unknown field %q", name)}
+				v.add(err)
+				v.unknown = append(v.unknown, err)
+			}
+		}
+	}
+}
+
+// allowsType checks the json type of a value against the schema,
+// yaml scalars are accepted for strings as the yaml decoder converts them
+func (s *Schema) allowsType(valueType string) bool {
+	types := s.types()
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		switch {
+		case t == valueType,
+			t == "number" && valueType == "integer",
+			t == "string" && (valueType == "integer" || valueType == "number" || valueType == "boolean"):
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) allowsValue(value interface{}) bool {
+	for _, allowed := range s.Enum {
+		if fmt.Sprintf("%v", allowed) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func (s *Schema) typeNames() string {
+	return strings.Join(s.types(), " or ")
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[interface{}]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -1,17 +1,30 @@
 package constants
 
 const (
-	TemplatesDir      = "tmp/templates"
-	ZeroProjectYml    = "zero-project.yml"
-	ZeroModuleYml     = "zero-module.yml"
-	ZeroHomeDirectory = ".zero"
-	IgnoredPaths      = "(?i)zero.module.yml|.git/"
-	TemplateExtn      = ".tmpl"
+	TemplatesDir        = "tmp/templates"
+	ZeroProjectYml      = "zero-project.yml"
+	ZeroProjectLocalYml = "zero-project.local.yml"
+	ZeroModuleYml       = "zero-module.yml"
+	ZeroSecretsFile     = "zero-project.secrets"
+	ZeroManifestFile    = ".zero/manifest.json"
+	ZeroHomeDirectory   = ".zero"
+	IgnoredPaths        = "(?i)zero.module.yml|.git/"
+	TemplateExtn        = ".tmpl"
 
 	// prompt constants
 
-	MaxPnameLength     = 16
-	RegexValidation    = "regex"
-	FunctionValidation = "function"
-	ZeroReleaseURL     = "https://github.com/commitdev/zero/releases"
+	MaxPnameLength      = 16
+	RegexValidation     = "regex"
+	FunctionValidation  = "function"
+	URLValidation       = "url"
+	EmailValidation     = "email"
+	DomainValidation    = "domain"
+	DNSLabelValidation  = "dns-label"
+	CIDRValidation      = "cidr"
+	PortValidation      = "port"
+	AWSRegionValidation = "aws-region"
+	MinLengthValidation = "minLength"
+	MaxLengthValidation = "maxLength"
+	RangeValidation     = "range"
+	ZeroReleaseURL      = "https://github.com/commitdev/zero/releases"
 )
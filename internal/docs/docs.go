@@ -0,0 +1,192 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+)
+
+// RenderModule returns the Markdown reference of a module generated from its zero-module.yml
+func RenderModule(cfg moduleconfig.ModuleConfig) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", cfg.Name)
+	if cfg.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", cfg.Description)
+	}
+	if cfg.Author != "" {
+		fmt.Fprintf(&b, "Author: %s\n\n", cfg.Author)
+	}
+	zeroVersion := cfg.ZeroVersion.String()
+	if zeroVersion == "" {
+		zeroVersion = "any"
+	}
+	fmt.Fprintf(&b, "Zero version: `%s`\n\n", zeroVersion)
+
+	b.WriteString("## Dependencies\n\n")
+	if len(cfg.DependsOn) == 0 {
+		b.WriteString("None\n\n")
+	} else {
+		for _, dependency := range cfg.DependsOn {
+			fmt.Fprintf(&b, "- `%s`\n", dependency)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Required credentials\n\n")
+	if len(cfg.RequiredCredentials) == 0 {
+		b.WriteString("None\n\n")
+	} else {
+		table := [][]string{{"Vendor", "Field", "Label", "Env var"}}
+		for _, vendor := range cfg.RequiredCredentials {
+			provider, err := cfg.GetCredentialProvider(vendor)
+			if err != nil {
+				return "", err
+			}
+			for _, field := range provider.Fields {
+				table = append(table, []string{code(vendor), code(field.Field), field.Label, code(field.EnvVarName)})
+			}
+		}
+		writeTable(&b, table)
+	}
+
+	b.WriteString("## Commands\n\n")
+	commands := map[string]string{
+		"check":   cfg.Commands.Check,
+		"apply":   cfg.Commands.Apply,
+		"summary": cfg.Commands.Summary,
+	}
+	defaultCommands := map[string]string{
+		"check":   moduleconfig.DefaultCommands.Check,
+		"apply":   moduleconfig.DefaultCommands.Apply,
+		"summary": moduleconfig.DefaultCommands.Summary,
+	}
+	table := [][]string{{"Phase", "Command"}}
+	for _, phase := range []string{"check", "apply", "summary"} {
+		command := code(commands[phase])
+		if commands[phase] == "" {
+			command = code(defaultCommands[phase]) + " (default)"
+		}
+		table = append(table, []string{phase, command})
+	}
+	writeTable(&b, table)
+
+	b.WriteString("## Parameters\n\n")
+	if len(cfg.Parameters) == 0 {
+		b.WriteString("None\n\n")
+	} else {
+		envVarNames := cfg.GetParamEnvVarTranslationMap()
+		table := [][]string{{"Field", "Label", "Type", "Default", "Options", "Validation", "Conditions", "Env var", "In project file"}}
+		for _, p := range cfg.Parameters {
+			envVarName := envVarNames[p.Field]
+			if envVarName == "" {
+				envVarName = p.Field
+			}
+			table = append(table, []string{
+				code(p.Field),
+				p.Label,
+				parameterType(p),
+				parameterDefault(p),
+				options(p),
+				validation(p.FieldValidation),
+				conditions(p.Conditions),
+				code(envVarName),
+				projectFile(p),
+			})
+		}
+		writeTable(&b, table)
+	}
+
+	if len(cfg.Conditions) > 0 {
+		b.WriteString("## File conditions\n\n")
+		table := [][]string{{"Action", "When", "Data"}}
+		for _, c := range cfg.Conditions {
+			data := []string{}
+			for _, d := range c.Data {
+				data = append(data, code(d))
+			}
+			table = append(table, []string{code(c.Action), code(c.Expression.String()), strings.Join(data, ", ")})
+		}
+		writeTable(&b, table)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func parameterType(p moduleconfig.Parameter) string {
+	if p.Type == "" {
+		return moduleconfig.TypeString
+	}
+	return p.Type
+}
+
+func parameterDefault(p moduleconfig.Parameter) string {
+	switch {
+	case p.Value != "":
+		return code(p.Value) + " (fixed)"
+	case p.Execute != "":
+		return "output of " + code(p.Execute)
+	}
+	return code(p.Default)
+}
+
+func options(p moduleconfig.Parameter) string {
+	items := []string{}
+	for _, option := range p.Options {
+		items = append(items, fmt.Sprintf("%s (%v)", code(fmt.Sprintf("%v", option.Key)), option.Value))
+	}
+	return strings.Join(items, ", ")
+}
+
+func validation(v moduleconfig.Validate) string {
+	if v.Type == "" {
+		return ""
+	}
+	if v.Value == "" {
+		return v.Type
+	}
+	return fmt.Sprintf("%s %s", v.Type, code(v.Value))
+}
+
+func conditions(conditions []moduleconfig.Condition) string {
+	items := []string{}
+	for _, c := range conditions {
+		items = append(items, code(c.Expression.String()))
+	}
+	return strings.Join(items, " and ")
+}
+
+func projectFile(p moduleconfig.Parameter) string {
+	switch {
+	case p.OmitFromProjectFile:
+		return "no"
+	case p.Secret:
+		return "secrets file"
+	}
+	return "yes"
+}
+
+// code formats a value as inline code, empty values are left empty
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + value + "`"
+}
+
+// writeTable writes a Markdown table, the first row is the header
+func writeTable(b *strings.Builder, rows [][]string) {
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cell = strings.Replace(cell, "|", `\|`, -1)
+			cells[j] = strings.TrimSpace(strings.Replace(cell, "\n", " ", -1))
+		}
+		fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+		if i == 0 {
+			fmt.Fprintf(b, "|%s\n", strings.Repeat("---|", len(row)))
+		}
+	}
+	b.WriteString("\n")
+}
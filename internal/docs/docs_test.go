@@ -0,0 +1,28 @@
+package docs_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/docs"
+	"github.com/commitdev/zero/internal/module"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderModule(t *testing.T) {
+	mod, err := module.ParseModuleConfig("../../tests/test_data/modules/ci")
+	assert.NoError(t, err)
+
+	content, err := docs.RenderModule(mod)
+	assert.NoError(t, err)
+
+	assert.Contains(t, content, "# CI templates\n\nCI description\n\nAuthor: CI author\n\nZero version: `>= 3.0.0, < 4.0.0`\n")
+	assert.Contains(t, content, "## Dependencies\n\nNone\n")
+	assert.Contains(t, content, "| `circleci` | `circleciApiKey` | CircleCI API Key | `CIRCLECI_API_KEY` |\n")
+	assert.Contains(t, content, "| check | `ls` |\n| apply | `make` (default) |\n")
+	assert.Contains(t, content, "| `platform` | CI Platform | string |  | `github` (Github), `circleci` (Circle CI) |  |  | `platform` | yes |\n")
+	assert.Contains(t, content, "| `circleci_api_key` | Circle CI API Key to setup your CI/CD for repositories | string |  |  |  | `platform equals \"circlci\"` | `circleci_api_key` | secrets file |\n")
+	assert.Contains(t, content, "| `accessKeyId` | AWS AccessKeyId | string |  |  |  | `useExistingAwsProfile equals \"no\"` | `AWS_ACCESS_KEY_ID` | yes |\n")
+	assert.Contains(t, content, "| `useExistingAwsProfile` |")
+	assert.Contains(t, content, "| no |\n")
+	assert.Contains(t, content, "| `testExecute` |  | string | output of `echo $AWS_ACCESS_KEY_ID` |")
+}
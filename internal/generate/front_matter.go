@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// frontMatterStart and frontMatterEnd surround the front matter block at the top of a template,
+// `---zero` is used instead of `---` so YAML documents can still start with a document marker
+const (
+	frontMatterStart = "---zero"
+	frontMatterEnd   = "---"
+)
+
+// frontMatter is the optional per-file configuration of a template, it is stripped from the output
+type frontMatter struct {
+	// Path replaces the output path of the file, relative to the module's output
+	Path string `yaml:"path,omitempty"`
+	// Mode of the output file in octal, eg: "0755"
+	Mode string `yaml:"mode,omitempty"`
+	// Condition skips the file unless it is met
+	Condition *projectconfig.Expression `yaml:"condition,omitempty"`
+	// Delimiters replace the module's delimiters for this file
+	Delimiters []string `yaml:"delimiters,omitempty"`
+	// Vars are available to the template as .Vars
+	Vars map[string]interface{} `yaml:"vars,omitempty"`
+}
+
+// splitFrontMatter separates the front matter of a template from its body, files without front matter have a nil front matter
+func splitFrontMatter(content []byte) (*frontMatter, []byte, error) {
+	line, offset := nextLine(content, 0)
+	if line != frontMatterStart {
+		return nil, content, nil
+	}
+
+	block := []string{}
+	for offset < len(content) {
+		line, offset = nextLine(content, offset)
+		if line == frontMatterEnd {
+			fm := &frontMatter{}
+			if err := yaml.UnmarshalStrict([]byte(strings.Join(block, "\n")), fm); err != nil {
+				return nil, nil, fmt.Errorf("invalid front matter: %v", err)
+			}
+			if err := fm.validate(); err != nil {
+				return nil, nil, fmt.Errorf("invalid front matter: %v", err)
+			}
+			return fm, content[offset:], nil
+		}
+		block = append(block, line)
+	}
+	return nil, nil, fmt.Errorf("front matter is missing its closing %s", frontMatterEnd)
+}
+
+// nextLine returns the line of content starting at start without its line ending, either \n or \r\n,
+// and the start of the following line
+func nextLine(content []byte, start int) (string, int) {
+	end := bytes.IndexByte(content[start:], '\n')
+	if end < 0 {
+		return strings.TrimSuffix(string(content[start:]), "\r"), len(content)
+	}
+	return strings.TrimSuffix(string(content[start:start+end]), "\r"), start + end + 1
+}
+
+// readFrontMatter returns the front matter of a template file
+func readFrontMatter(filePath string) (*frontMatter, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fm, _, err := splitFrontMatter(content)
+	return fm, err
+}
+
+func (fm *frontMatter) validate() error {
+	if fm.Path != "" {
+		cleaned := path.Clean(fm.Path)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("path %s is outside of the module's output", fm.Path)
+		}
+	}
+	if fm.Mode != "" {
+		if _, err := fm.fileMode(); err != nil {
+			return err
+		}
+	}
+	if fm.Condition != nil {
+		if err := fm.Condition.Validate(); err != nil {
+			return fmt.Errorf("condition: %v", err)
+		}
+	}
+	if len(fm.Delimiters) != 0 && len(fm.Delimiters) != 2 {
+		return fmt.Errorf("delimiters must be a pair of open and close delimiters")
+	}
+	return nil
+}
+
+func (fm *frontMatter) fileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(fm.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("mode %s is not an octal file mode, eg: 0755", fm.Mode)
+	}
+	return os.FileMode(mode), nil
+}
@@ -1,29 +1,50 @@
 package generate
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"text/template"
 
 	"github.com/commitdev/zero/internal/condition"
+	"github.com/commitdev/zero/internal/config/moduleconfig"
 	"github.com/commitdev/zero/internal/config/projectconfig"
 	"github.com/commitdev/zero/internal/constants"
 	"github.com/commitdev/zero/internal/module"
 	"github.com/commitdev/zero/internal/util"
 	"github.com/commitdev/zero/pkg/util/flog"
 	"github.com/commitdev/zero/pkg/util/fs"
+	"github.com/commitdev/zero/version"
 
 	"github.com/gabriel-vasile/mimetype"
 )
 
-// Generate accepts a projectconfig struct and renders the templates for all referenced modules
-func Generate(projectConfig projectconfig.ZeroProjectConfig, overwriteFiles bool) error {
+// RenderErrors are the errors of all the files that failed to render
+type RenderErrors []error
+
+func (errs RenderErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to render %d file(s):\n\t%s", len(errs), strings.Join(messages, "\n\t"))
+}
+
+// Generate accepts a projectconfig struct and renders the templates for all referenced modules.
+// Files are rendered into a staging directory first and are only moved into place if every file of every module succeeded,
+// otherwise the errors of all the files are returned as RenderErrors and nothing is written.
+// In strict mode every template fails on missing keys, regardless of the module's template.missingKey.
+// The returned manifest describes the files that were written, see WriteManifest
+func Generate(projectConfig projectconfig.ZeroProjectConfig, overwriteFiles bool, strict bool) (Manifest, error) {
 	flog.Infof(":clock: Fetching Modules")
 
 	// Make sure module sources are on disk
@@ -34,109 +55,487 @@ func Generate(projectConfig projectconfig.ZeroProjectConfig, overwriteFiles bool
 	}
 	wg.Wait()
 
+	stagingDir, err := ioutil.TempDir("", "zero-create")
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer os.RemoveAll(stagingDir)
+
 	flog.Infof(":memo: Rendering Modules")
-	for _, mod := range projectConfig.Modules {
+	modules := getModulesData(projectConfig.Modules)
+	manifest := Manifest{ZeroVersion: version.AppVersion, Files: []ManifestEntry{}}
+	renderErrors := RenderErrors{}
+	staged := []*fileConfig{}
+	for moduleName, mod := range projectConfig.Modules {
 		// Load module configuration
 		moduleConfig, err := module.ParseModuleConfig(mod.Files.Source)
 		if err != nil {
-			return fmt.Errorf("unable to load module:  %v", err)
+			return Manifest{}, fmt.Errorf("unable to load module:  %v", err)
+		}
+		if err := moduleConfig.ValidateParameters(mod.Parameters); err != nil {
+			return Manifest{}, err
 		}
 
 		moduleDir := path.Join(module.GetSourceDir(mod.Files.Source), moduleConfig.InputDir)
-		delimiters := moduleConfig.Delimiters
+		partialsDir := ""
+		if moduleConfig.PartialsDir != "" {
+			partialsDir = path.Join(module.GetSourceDir(mod.Files.Source), moduleConfig.PartialsDir)
+		}
+		leftDelim, rightDelim := getDelimiters(moduleConfig.Delimiters)
 		outputDir := mod.Files.Directory
+		moduleStagingDir := filepath.Join(stagingDir, moduleName)
 
 		// Data that will be passed in to each template
-		templateData := struct {
-			Name       string
-			Params     projectconfig.Parameters
-			Files      projectconfig.Files
-			Conditions []projectconfig.Condition
-		}{
-			projectConfig.Name,
-			mod.Parameters,
-			mod.Files,
-			mod.Conditions,
+		templateData := TemplateData{
+			Name:          projectConfig.Name,
+			Params:        mod.Parameters,
+			Files:         mod.Files,
+			Conditions:    mod.Conditions,
+			Modules:       modules,
+			ProjectParams: projectConfig.Parameters,
+			Environments:  projectConfig.Environments,
+			ZeroVersion:   version.AppVersion,
+			Module: ModuleMetadata{
+				Key:      moduleName,
+				Name:     moduleConfig.Name,
+				Source:   mod.Files.Source,
+				Revision: module.GetSourceRevision(mod.Files.Source),
+			},
+		}
+
+		partials, err := loadPartials(partialsDir, leftDelim, rightDelim)
+		if err != nil {
+			renderErrors = append(renderErrors, fmt.Errorf("%s: %v", moduleConfig.Name, err))
+			continue
+		}
+
+		files, err := getModuleFiles(moduleDir, partialsDir, mod)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("unable to list the files of module %s: %v", moduleConfig.Name, err)
+		}
+		missingKey := moduleConfig.MissingKey
+		if strict {
+			missingKey = moduleconfig.MissingKeyError
+		}
+		files, errs := renderFilePaths(files, templateData, partials, leftDelim, rightDelim, missingKey)
+		if len(errs) > 0 {
+			for _, err := range errs {
+				renderErrors = append(renderErrors, fmt.Errorf("%s: %v", moduleConfig.Name, err))
+			}
+			continue
+		}
+		txtTypeFiles, binTypeFiles, err := sortFileType(moduleDir, outputDir, files, overwriteFiles, moduleConfig.StrictMode, mod.Parameters)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("unable to read the files of module %s: %v", moduleConfig.Name, err)
+		}
+
+		moduleFiles := append(txtTypeFiles, binTypeFiles...)
+		if errs := checkDestinations(moduleFiles); len(errs) > 0 {
+			for _, err := range errs {
+				renderErrors = append(renderErrors, fmt.Errorf("%s: %v", moduleConfig.Name, err))
+			}
+			continue
+		}
+		for _, f := range moduleFiles {
+			f.staged = filepath.Join(moduleStagingDir, filepath.FromSlash(f.relativeDestination))
 		}
 
-		txtTypeFiles, binTypeFiles := sortFileType(moduleDir, outputDir, overwriteFiles)
+		errs = executeTemplates(txtTypeFiles, templateData, partials, leftDelim, rightDelim, missingKey)
+		errs = append(errs, copyBinFiles(binTypeFiles)...)
+		for _, err := range errs {
+			renderErrors = append(renderErrors, fmt.Errorf("%s: %v", moduleConfig.Name, err))
+		}
+		if len(errs) > 0 {
+			continue
+		}
 
-		executeTemplates(txtTypeFiles, templateData, delimiters)
-		copyBinFiles(binTypeFiles)
+		rendered := []string{}
+		for _, f := range moduleFiles {
+			rendered = append(rendered, f.relativeDestination)
+		}
+		if err := condition.ApplyToOutput(mod.Conditions, mod, moduleStagingDir, rendered); err != nil {
+			renderErrors = append(renderErrors, fmt.Errorf("%s: unable to apply conditions: %v", moduleConfig.Name, err))
+			continue
+		}
 
-		for _, cond := range mod.Conditions {
-			condition.Perform(cond, mod)
+		paramsHash, err := hashParameters(modules[moduleName].Params)
+		if err != nil {
+			return Manifest{}, err
 		}
+		for _, f := range moduleFiles {
+			contentHash, err := hashFile(f.staged)
+			if err != nil {
+				return Manifest{}, err
+			}
+			manifest.Files = append(manifest.Files, ManifestEntry{
+				Module:      moduleName,
+				Revision:    templateData.Module.Revision,
+				Template:    f.relativeSource,
+				Output:      filepath.ToSlash(f.destination),
+				Mode:        fmt.Sprintf("%04o", f.modeBits),
+				ContentHash: contentHash,
+				ParamsHash:  paramsHash,
+			})
+		}
+		staged = append(staged, moduleFiles...)
 	}
-	return nil
+
+	if len(renderErrors) > 0 {
+		return Manifest{}, renderErrors
+	}
+
+	for _, f := range staged {
+		if err := moveFile(f.staged, f.destination); err != nil {
+			return Manifest{}, fmt.Errorf("unable to write %s: %v", f.destination, err)
+		}
+		flog.Successf("Finished rendering : %s", f.destination)
+	}
+	return manifest, nil
 }
 
-type fileConfig struct {
-	source      string
-	destination string
-	modeBits    os.FileMode
+// TemplateData is the data passed in to each template
+type TemplateData struct {
+	Name       string
+	Params     projectconfig.Parameters
+	Files      projectconfig.Files
+	Conditions []projectconfig.Condition
+	// Vars are the variables of the file's front matter
+	Vars map[string]interface{}
+	// Modules are all the modules of the project by their name in the project file, so templates can refer to each other's
+	// repositories and settings, eg: {{ .Modules.backend.Params.apiHost }}
+	Modules map[string]ModuleData
+	// ProjectParams are the top-level parameters of the project file
+	ProjectParams projectconfig.Parameters
+	Environments  []string
+	ZeroVersion   string
+	// Module describes the module being rendered
+	Module ModuleMetadata
 }
 
-// sortFileType walks the module directory to find and classify all files into bin / text/plain (non-bin) types.
-func sortFileType(moduleDir string, outputDir string, overwrite bool) ([]*fileConfig, []*fileConfig) {
-	binTypeFiles := []*fileConfig{}
-	txtTypeFiles := []*fileConfig{}
+// ModuleData is what templates can see of the other modules, their secrets are left out
+type ModuleData struct {
+	Files  projectconfig.Files
+	Params projectconfig.Parameters
+}
+
+// ModuleMetadata describes the module being rendered
+type ModuleMetadata struct {
+	// Key is the name of the module in the project file
+	Key string
+	// Name is the name of the module in its zero-module.yml
+	Name   string
+	Source string
+	// Revision is the commit or ref of the module's source, see module.GetSourceRevision
+	Revision string
+}
 
+// getModulesData returns the files and parameters of the project's modules without their secrets
+func getModulesData(modules projectconfig.Modules) map[string]ModuleData {
+	data := make(map[string]ModuleData, len(modules))
+	for name, mod := range modules {
+		params := projectconfig.Parameters{}
+		for key, value := range mod.Parameters {
+			if _, isSecret := mod.Secrets[key]; !isSecret {
+				params[key] = value
+			}
+		}
+		data[name] = ModuleData{Files: mod.Files, Params: params}
+	}
+	return data
+}
+
+type fileConfig struct {
+	source              string
+	relativeSource      string
+	destination         string
+	relativeDestination string
+	staged              string
+	modeBits            os.FileMode
+}
+
+// getModuleFiles lists the files of the module directory to render,
+// excluding the module's own files and partials and applying the module's conditions
+func getModuleFiles(moduleDir string, partialsDir string, mod projectconfig.Module) ([]condition.File, error) {
 	paths, err := getAllFilePathsInDirectory(moduleDir)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	ignoredPaths := regexp.MustCompile(constants.IgnoredPaths)
+	files := []condition.File{}
 	for _, path := range paths {
-		ignoredPaths, _ := regexp.Compile(constants.IgnoredPaths)
 		if ignoredPaths.MatchString(path) {
 			continue
 		}
+		if partialsDir != "" {
+			if relativeToPartials, err := filepath.Rel(partialsDir, path); err == nil && !strings.HasPrefix(relativeToPartials, "..") {
+				continue
+			}
+		}
+		relativePath, err := filepath.Rel(moduleDir, path)
+		if err != nil {
+			return nil, err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		files = append(files, condition.File{Source: relativePath, Destination: relativePath})
+	}
 
-		outputPath := fs.ReplacePath(path, moduleDir, outputDir)
+	return condition.ApplyToFiles(mod.Conditions, mod, files)
+}
 
-		if !overwrite {
-			if exists, _ := fs.FileExists(outputPath); exists {
-				flog.Warnf("%v already exists. skipping.", outputPath)
+// loadPartials parses the files of the partials directory into a template set shared by all the module's templates,
+// each file is available as a template named after its path relative to the directory, eg: {{ template "labels.yaml" . }}
+func loadPartials(partialsDir string, leftDelim string, rightDelim string) (*template.Template, error) {
+	partials := template.New("").Delims(leftDelim, rightDelim).Funcs(util.FuncMap).Funcs(template.FuncMap{
+		// replaced for each template by newTemplate
+		"include": func(string, interface{}) (string, error) { return "", nil },
+	})
+	if partialsDir == "" {
+		return partials, nil
+	}
+
+	paths, err := getAllFilePathsInDirectory(partialsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, partialPath := range paths {
+		content, err := ioutil.ReadFile(partialPath)
+		if err != nil {
+			return nil, err
+		}
+		name, err := filepath.Rel(partialsDir, partialPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := partials.New(filepath.ToSlash(name)).Parse(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return partials, nil
+}
+
+// newTemplate parses a template with the partials in its namespace, `include` renders a partial or defined
+// template to a string so it can be piped, eg: {{ include "labels.yaml" . | nindent 4 }}
+func newTemplate(partials *template.Template, name string, leftDelim string, rightDelim string, missingKey moduleconfig.MissingKey, text string) (*template.Template, error) {
+	namespace, err := partials.Clone()
+	if err != nil {
+		return nil, err
+	}
+	namespace.Funcs(template.FuncMap{
+		"include": func(name string, data interface{}) (string, error) {
+			var out strings.Builder
+			err := namespace.ExecuteTemplate(&out, name, data)
+			return out.String(), err
+		},
+	})
+	template, err := namespace.New(name).Delims(leftDelim, rightDelim).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// missing keys are rendered empty by executeWithMissingKeys, which needs them to be errors to find them
+	if missingKey == moduleconfig.MissingKeyZero {
+		missingKey = moduleconfig.MissingKeyError
+	}
+	setMissingKey(template, missingKey)
+	return template, nil
+}
+
+// setMissingKey sets the missingkey option of a template and of the templates of its namespace,
+// the option is per template, partials are executed with their own
+func setMissingKey(tmpl *template.Template, missingKey moduleconfig.MissingKey) {
+	if missingKey == "" {
+		missingKey = moduleconfig.MissingKeyDefault
+	}
+	for _, t := range tmpl.Templates() {
+		t.Option("missingkey=" + string(missingKey))
+	}
+}
+
+// getDelimiters returns the template delimiters of the module, defaulting to {{ and }}
+func getDelimiters(delimiters []string) (string, string) {
+	leftDelim, rightDelim := "{{", "}}"
+	if len(delimiters) > 0 && delimiters[0] != "" {
+		leftDelim = delimiters[0]
+	}
+	if len(delimiters) > 1 && delimiters[1] != "" {
+		rightDelim = delimiters[1]
+	}
+	return leftDelim, rightDelim
+}
+
+// renderFilePaths renders the segments of the output paths containing template delimiters, eg: `services/<% .Name %>-api/`.
+// Segments are rendered like templates, with the partials, and missing keys render as empty strings unless missingKey is error.
+// Files with a segment rendering to an empty string are dropped. It returns the errors of all the paths that failed
+func renderFilePaths(files []condition.File, data TemplateData, partials *template.Template, leftDelim string, rightDelim string, missingKey moduleconfig.MissingKey) ([]condition.File, []error) {
+	if missingKey != moduleconfig.MissingKeyError {
+		missingKey = moduleconfig.MissingKeyZero
+	}
+	rendered := []condition.File{}
+	errs := []error{}
+	for _, file := range files {
+		if !strings.Contains(file.Destination, leftDelim) {
+			rendered = append(rendered, file)
+			continue
+		}
+
+		segments := strings.Split(file.Destination, "/")
+		skip := false
+		var segmentErrs []error
+		for i, segment := range segments {
+			if !strings.Contains(segment, leftDelim) {
+				continue
+			}
+			tmpl, err := newTemplate(partials, file.Source, leftDelim, rightDelim, missingKey, segment)
+			if err != nil {
+				segmentErrs = append(segmentErrs, err)
 				continue
 			}
+			out, executeErrs := executeWithMissingKeys(tmpl, data, missingKey)
+			if len(executeErrs) > 0 {
+				segmentErrs = append(segmentErrs, executeErrs...)
+				continue
+			}
+			if len(out) == 0 {
+				skip = true
+				break
+			}
+			segments[i] = string(out)
 		}
+		if len(segmentErrs) > 0 {
+			errs = append(errs, segmentErrs...)
+			continue
+		}
+		if skip {
+			flog.Debugf("Skipping %s, its path renders to an empty name", file.Source)
+			continue
+		}
+
+		destination := path.Clean(strings.Join(segments, "/"))
+		if destination == ".." || strings.HasPrefix(destination, "../") || path.IsAbs(destination) {
+			errs = append(errs, fmt.Errorf("%s renders to %s, outside of the module's output", file.Source, destination))
+			continue
+		}
+		file.Destination = destination
+		rendered = append(rendered, file)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return rendered, nil
+}
+
+// checkDestinations returns an error for each output path that more than one file renders to, the final paths are checked
+// as templated names, front matter paths and the removal of the template extension can all make files collide
+func checkDestinations(files []*fileConfig) []error {
+	errs := []error{}
+	sources := map[string]string{}
+	for _, f := range files {
+		if source, ok := sources[f.relativeDestination]; ok {
+			errs = append(errs, fmt.Errorf("%s and %s both render to %s", source, f.relativeSource, f.relativeDestination))
+			continue
+		}
+		sources[f.relativeDestination] = f.relativeSource
+	}
+	return errs
+}
+
+// sortFileType classifies the files of the module directory into bin / text/plain (non-bin) types.
+// In strict mode only the files with the template extension are rendered, with the extension removed
+// from their output name, every other file is copied as is.
+// The front matter of templates is applied here, as it can change the output path or skip the file
+func sortFileType(moduleDir string, outputDir string, files []condition.File, overwrite bool, strictMode bool, params projectconfig.Parameters) ([]*fileConfig, []*fileConfig, error) {
+	binTypeFiles := []*fileConfig{}
+	txtTypeFiles := []*fileConfig{}
+	values := params.Strings()
 
+	for _, file := range files {
+		path := filepath.Join(moduleDir, filepath.FromSlash(file.Source))
 		fileInfo, err := os.Stat(path)
 		if err != nil {
-			panic(err)
+			return nil, nil, err
 		}
-
-		// detect the file type
-		detectedMIME, err := mimetype.DetectFile(path)
+		isTemplate, err := isTemplateFile(moduleDir, file, strictMode)
 		if err != nil {
-			panic(err)
+			return nil, nil, err
+		}
+		if isTemplate && strictMode {
+			file.Destination, _ = stripTemplateExtension(file.Destination)
 		}
 
-		// detect root file type
-		isBinary := true
-		for mime := detectedMIME; mime != nil; mime = mime.Parent() {
-			if mime.Is("text/plain") {
-				isBinary = false
+		config := &fileConfig{
+			source:         path,
+			relativeSource: file.Source,
+			modeBits:       fileInfo.Mode().Perm(),
+		}
+		if isTemplate {
+			fm, err := readFrontMatter(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", file.Source, err)
+			}
+			if fm != nil {
+				if fm.Condition != nil && !condition.Evaluate(*fm.Condition, values) {
+					flog.Infof("%s: skipped, front matter condition (%s) is not met", file.Source, fm.Condition)
+					continue
+				}
+				if fm.Path != "" {
+					file.Destination = filepath.ToSlash(filepath.Clean(fm.Path))
+				}
+				if fm.Mode != "" {
+					config.modeBits, _ = fm.fileMode()
+				}
 			}
 		}
+		config.relativeDestination = file.Destination
+		config.destination = filepath.Join(outputDir, filepath.FromSlash(file.Destination))
 
-		if isBinary {
-			binTypeFiles = append(binTypeFiles, &fileConfig{
-				source:      path,
-				destination: outputPath,
-				modeBits:    fileInfo.Mode().Perm(),
-			})
-			continue
+		if !overwrite {
+			if exists, _ := fs.FileExists(config.destination); exists {
+				flog.Warnf("%v already exists. skipping.", config.destination)
+				continue
+			}
 		}
 
-		txtTypeFiles = append(txtTypeFiles, &fileConfig{
-			source:      path,
-			destination: outputPath,
-			modeBits:    fileInfo.Mode().Perm(),
-		})
+		if isTemplate {
+			txtTypeFiles = append(txtTypeFiles, config)
+		} else {
+			binTypeFiles = append(binTypeFiles, config)
+		}
 	}
-	return txtTypeFiles, binTypeFiles
+	return txtTypeFiles, binTypeFiles, nil
+}
+
+// isTemplateFile returns whether a file of the module is rendered as a template, in strict mode only files with the template
+// extension are, otherwise any text file is. It is decided from the source of the file, so renaming it doesn't change it
+func isTemplateFile(moduleDir string, file condition.File, strictMode bool) (bool, error) {
+	if strictMode {
+		_, isTemplate := stripTemplateExtension(file.Source)
+		return isTemplate, nil
+	}
+	// detect the file type
+	detectedMIME, err := mimetype.DetectFile(filepath.Join(moduleDir, filepath.FromSlash(file.Source)))
+	if err != nil {
+		return false, err
+	}
+	// detect root file type
+	for mime := detectedMIME; mime != nil; mime = mime.Parent() {
+		if mime.Is("text/plain") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stripTemplateExtension removes the template extension from the file name of a path,
+// eg: `values.tmpl.yaml` becomes `values.yaml` and `Makefile.tmpl` becomes `Makefile`
+func stripTemplateExtension(filePath string) (string, bool) {
+	dir, name := path.Split(filePath)
+	if strings.HasSuffix(name, constants.TemplateExtn) && len(name) > len(constants.TemplateExtn) {
+		return dir + strings.TrimSuffix(name, constants.TemplateExtn), true
+	}
+	if i := strings.LastIndex(name, constants.TemplateExtn+"."); i > 0 {
+		return dir + name[:i] + name[i+len(constants.TemplateExtn):], true
+	}
+	return filePath, false
 }
 
 // getAllFilePathsInDirectory Recursively get all file paths in directory, including sub-directories.
@@ -158,85 +557,242 @@ func getAllFilePathsInDirectory(moduleDir string) ([]string, error) {
 	return paths, nil
 }
 
-func executeTemplates(templates []*fileConfig, data interface{}, delimiters []string) {
-	var wg sync.WaitGroup
-	leftDelim := delimiters[0]
-	rightDelim := delimiters[1]
-	if leftDelim == "" {
-		leftDelim = "{{"
+// executeTemplates renders the templates into their staged path, it returns the errors of all the templates that failed
+func executeTemplates(templates []*fileConfig, data TemplateData, partials *template.Template, leftDelim string, rightDelim string, missingKey moduleconfig.MissingKey) []error {
+	errs := []error{}
+	for _, tmpltConfig := range templates {
+		if templateErrs := executeTemplate(tmpltConfig, data, partials, leftDelim, rightDelim, missingKey); len(templateErrs) > 0 {
+			errs = append(errs, templateErrs...)
+			continue
+		}
+		flog.Debugf("Rendered %s", tmpltConfig.relativeDestination)
 	}
-	if rightDelim == "" {
-		rightDelim = "}}"
+	return errs
+}
+
+func executeTemplate(tmpltConfig *fileConfig, data TemplateData, partials *template.Template, leftDelim string, rightDelim string, missingKey moduleconfig.MissingKey) []error {
+	content, err := ioutil.ReadFile(tmpltConfig.source)
+	if err != nil {
+		return []error{err}
+	}
+	fm, body, err := splitFrontMatter(content)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %v", tmpltConfig.relativeSource, err)}
 	}
-	// flog.Infof("Templating params:")
-	// pp.Println(data)
+	if fm != nil {
+		data.Vars = fm.Vars
+		if len(fm.Delimiters) == 2 {
+			leftDelim, rightDelim = fm.Delimiters[0], fm.Delimiters[1]
+		}
+	}
+	// errors report the line in the file, including the front matter
+	frontMatterLines := strings.Count(string(content[:len(content)-len(body)]), "\n")
 
-	for _, tmpltConfig := range templates {
-		source := tmpltConfig.source
-		dest := tmpltConfig.destination
+	// templates are named after their path in the module so errors point to the file
+	template, err := newTemplate(partials, tmpltConfig.relativeSource, leftDelim, rightDelim, missingKey, string(body))
+	if err != nil {
+		return []error{offsetErrorLine(err, tmpltConfig.relativeSource, frontMatterLines)}
+	}
 
-		outputDirPath, _ := path.Split(dest)
-		err := fs.CreateDirs(outputDirPath)
-		if err != nil {
-			flog.Errorf("Error creating directory '%s': %v", source, err)
-		}
-		f, err := os.Create(dest)
-		if err != nil {
-			flog.Errorf("Error initializing file '%s'", err)
+	out, errs := executeWithMissingKeys(template, data, missingKey)
+	if len(errs) > 0 {
+		for i, err := range errs {
+			errs[i] = offsetErrorLine(err, tmpltConfig.relativeSource, frontMatterLines)
 		}
+		return errs
+	}
+	if err := writeStagedFile(tmpltConfig, bytes.NewReader(out)); err != nil {
+		return []error{err}
+	}
+	return nil
+}
 
-		err = f.Chmod(tmpltConfig.modeBits)
-		if err != nil {
-			flog.Errorf("Error changing mode bits '%s'", err)
+// executeWithMissingKeys executes a template created by newTemplate. Execution stops at the first missing key,
+// each one in the parameter maps, see fillMissingKey, is filled in with an empty string so the template can be executed
+// again to find the next.
+// With missingKey: error each missing key is reported, with missingKey: zero they are rendered as empty strings
+func executeWithMissingKeys(tmpl *template.Template, data TemplateData, missingKey moduleconfig.MissingKey) ([]byte, []error) {
+	var out bytes.Buffer
+	errs := []error{}
+	for missing := 0; ; missing++ {
+		out.Reset()
+		err := tmpl.Execute(&out, data)
+		if err == nil {
+			break
+		}
+		key, filled := fillMissingKey(err, &data)
+		if key == "" {
+			return nil, append(errs, err)
+		}
+		if missingKey == moduleconfig.MissingKeyError {
+			errs = append(errs, errors.New(key))
+			if !filled || len(errs) >= maxMissingKeys {
+				break
+			}
+			continue
 		}
+		// keys missing from other maps, eg: .Modules, render as their zero value
+		if !filled || missing >= maxMissingKeys {
+			setMissingKey(tmpl, moduleconfig.MissingKeyZero)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out.Bytes(), nil
+}
 
-		// @TODO if strict mode then only copy file
-		name := path.Base(source)
-		template, err := template.New(name).Delims(leftDelim, rightDelim).Funcs(util.FuncMap).ParseFiles(source)
-		err = template.Execute(f, data)
+// maxMissingKeys is the number of missing keys reported, or filled in, per file
+const maxMissingKeys = 50
 
-		if err != nil {
-			flog.Errorf("Error templating '%s': %v", source, err)
-		} else {
-			flog.Successf("Finished templating : %s", dest)
+var missingKeyPattern = regexp.MustCompile(`^(template: [^:]+:\d+):\d+: executing "[^"]*" at <\$?(\.[^>]+)>: map has no entry for key "[^"]*"$`)
+
+// fillMissingKey describes the key of a missing key error, eg: `template: main.tf:3: undefined key .Params.fooo`,
+// and sets the key to an empty string in data when it is in one of the parameter maps: .Params, .Vars, .ProjectParams
+// or .Modules.<module>.Params, it returns "" for any other error
+func fillMissingKey(err error, data *TemplateData) (string, bool) {
+	match := missingKeyPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return "", false
+	}
+	key := fmt.Sprintf("%s: undefined key %s", match[1], match[2])
+
+	fields := strings.Split(strings.TrimPrefix(match[2], "."), ".")
+	var filled map[string]interface{}
+	var ok bool
+	switch fields[0] {
+	case "Params":
+		filled, ok = withKey(data.Params, fields[1:])
+		data.Params = filled
+	case "Vars":
+		filled, ok = withKey(data.Vars, fields[1:])
+		data.Vars = filled
+	case "ProjectParams":
+		filled, ok = withKey(data.ProjectParams, fields[1:])
+		data.ProjectParams = filled
+	case "Modules":
+		// a missing module is filled in as a module without parameters
+		if len(fields) < 3 || fields[2] != "Params" {
+			return key, false
+		}
+		module := data.Modules[fields[1]]
+		if module.Params, ok = withKey(module.Params, fields[3:]); !ok {
+			return key, false
 		}
+		modules := make(map[string]ModuleData, len(data.Modules)+1)
+		for name, m := range data.Modules {
+			modules[name] = m
+		}
+		modules[fields[1]] = module
+		data.Modules = modules
 	}
+	return key, ok
+}
 
-	wg.Wait()
+// withKey returns a copy of values with the nested key set to an empty string, missing parents of the key are
+// filled in as empty maps. It returns values unchanged when a parent of the key is not a map
+func withKey(values map[string]interface{}, keys []string) (map[string]interface{}, bool) {
+	if len(keys) == 0 {
+		return values, false
+	}
+	copied := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		copied[k] = v
+	}
+	if len(keys) == 1 {
+		copied[keys[0]] = ""
+		return copied, true
+	}
+	var nested map[string]interface{}
+	if parent, exists := values[keys[0]]; exists {
+		var ok bool
+		if nested, ok = parent.(map[string]interface{}); !ok {
+			return values, false
+		}
+	}
+	nested, ok := withKey(nested, keys[1:])
+	if !ok {
+		return values, false
+	}
+	copied[keys[0]] = nested
+	return copied, true
 }
 
-func copyBinFiles(binTypeFiles []*fileConfig) {
-	for _, binFile := range binTypeFiles {
-		source := binFile.source
-		dest := binFile.destination
+// offsetErrorLine adds the lines of the front matter to the line of a template error, eg: `template: main.tf:3: ...`
+func offsetErrorLine(err error, name string, offset int) error {
+	if offset == 0 {
+		return err
+	}
+	pattern := regexp.MustCompile(regexp.QuoteMeta(name) + `:(\d+)`)
+	message := err.Error()
+	if match := pattern.FindStringSubmatchIndex(message); match != nil {
+		line, _ := strconv.Atoi(message[match[2]:match[3]])
+		message = message[:match[2]] + strconv.Itoa(line+offset) + message[match[3]:]
+	}
+	return errors.New(message)
+}
 
-		// create dir
-		outputDirPath, _ := path.Split(dest)
-		err := fs.CreateDirs(outputDirPath)
+// copyBinFiles copies the files as is into their staged path
+func copyBinFiles(binTypeFiles []*fileConfig) []error {
+	errs := []error{}
+	for _, binFile := range binTypeFiles {
+		from, err := os.Open(binFile.source)
 		if err != nil {
-			flog.Errorf("Error creating directory '%s': %v", source, err)
+			errs = append(errs, err)
+			continue
 		}
-
-		// create refs to src and dest
-		from, err := os.Open(source)
+		err = writeStagedFile(binFile, from)
+		from.Close()
 		if err != nil {
-			flog.Errorf("Error opening file to read '%s' : %v", source, err)
+			errs = append(errs, err)
+			continue
 		}
-		defer from.Close()
+		flog.Debugf("Copied %s", binFile.relativeDestination)
+	}
+	return errs
+}
 
-		to, err := os.OpenFile(dest, os.O_RDWR|os.O_CREATE, binFile.modeBits)
-		if err != nil {
-			log.Fatal(err)
-			flog.Errorf("Error creating file '%s': %v", dest, err)
-		}
-		defer to.Close()
+func writeStagedFile(file *fileConfig, content io.Reader) error {
+	if err := fs.CreateDirs(filepath.Dir(file.staged)); err != nil {
+		return fmt.Errorf("%s: %v", file.relativeSource, err)
+	}
+	to, err := os.OpenFile(file.staged, os.O_RDWR|os.O_CREATE|os.O_TRUNC, file.modeBits)
+	if err != nil {
+		return fmt.Errorf("%s: %v", file.relativeSource, err)
+	}
+	defer to.Close()
+	if _, err := io.Copy(to, content); err != nil {
+		return fmt.Errorf("%s: %v", file.relativeSource, err)
+	}
+	// the mode of created files is subject to umask
+	return to.Chmod(file.modeBits)
+}
 
-		// copy file
-		_, err = io.Copy(to, from)
-		if err != nil {
-			flog.Errorf("Error copying file '%s' : %v", source, err)
-		} else {
-			flog.Successf("Finished copying file : %s", dest)
-		}
+// moveFile moves a staged file into place, copying it when the staging directory is on another device
+func moveFile(source string, destination string) error {
+	if err := fs.CreateDirs(filepath.Dir(destination)); err != nil {
+		return err
+	}
+	if err := os.Rename(source, destination); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	from, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer from.Close()
+	to, err := os.OpenFile(destination, os.O_RDWR|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer to.Close()
+	if _, err := io.Copy(to, from); err != nil {
+		return err
 	}
+	return to.Chmod(info.Mode().Perm())
 }
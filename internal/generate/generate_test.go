@@ -1,13 +1,17 @@
 package generate_test
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/commitdev/zero/internal/config/projectconfig"
 	"github.com/commitdev/zero/internal/generate"
+	"github.com/commitdev/zero/internal/module"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +26,32 @@ func setupTeardown(t *testing.T) (func(t *testing.T), string) {
 	}, tmpDir
 }
 
+// moduleConfig returns the zero-module.yml of a module, options are added to its template section
+func moduleConfig(name string, options ...string) string {
+	config := fmt.Sprintf("name: %s\ndescription: d\nauthor: a\ntemplate:\n  inputDir: templates\n  outputDir: out\n", name)
+	for _, option := range options {
+		config += "  " + option + "\n"
+	}
+	return config + "requiredCredentials:\nparameters:\n"
+}
+
+// writeModule creates a module in a temporary directory with the zero-module.yml config,
+// files are the content of its templates by their path in the inputDir.
+// It returns the module directory and a function to remove it
+func writeModule(t *testing.T, config string, files map[string]string) (string, func()) {
+	moduleDir, err := ioutil.TempDir("", "module")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(moduleDir, "zero-module.yml"), []byte(config), 0644))
+	for name, content := range files {
+		filePath := filepath.Join(moduleDir, "templates", filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+	}
+	return moduleDir, func() {
+		os.RemoveAll(moduleDir)
+	}
+}
+
 func TestGenerateModules(t *testing.T) {
 	teardown, tmpDir := setupTeardown(t)
 	defer teardown(t)
@@ -29,10 +59,11 @@ func TestGenerateModules(t *testing.T) {
 	projectConfig := projectconfig.ZeroProjectConfig{
 		Name: "foo",
 		Modules: projectconfig.Modules{
-			"mod1": projectconfig.NewModule(map[string]string{"test": "bar"}, tmpDir, "github.com/fake-org/repo-foo", baseTestFixturesDir, []string{}, []projectconfig.Condition{}),
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"test": "bar", "subdomains": []interface{}{"api", "www"}, "enabled": true}, tmpDir, "github.com/fake-org/repo-foo", baseTestFixturesDir, []string{}, []projectconfig.Condition{}),
 		},
 	}
-	generate.Generate(projectConfig, true)
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
 
 	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "file_to_template.txt"))
 	assert.NoError(t, err)
@@ -40,6 +71,396 @@ func TestGenerateModules(t *testing.T) {
 	expectedContent := `Name is foo
 Params.test is bar
 Files.Repository is github.com/fake-org/repo-foo
+Subdomain api
+Subdomain www
+Enabled
 `
 	assert.Equal(t, string(content), expectedContent)
+
+	_, err = os.Stat(filepath.Join(tmpDir, "file_to_template.tmpl.txt"))
+	assert.True(t, os.IsNotExist(err), "the template extension is removed from the output")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "services", "foo-api", "README.md"))
+	assert.NoError(t, err, "templated directory names are rendered")
+
+	files, err := ioutil.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	for _, file := range files {
+		assert.NotContains(t, file.Name(), "disabled", "paths rendering to an empty segment are skipped")
+	}
+
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "custom", "renamed.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello foo {{ kept as is }}\n", string(content), "front matter is stripped and sets the path, delimiters and vars")
+	info, err := os.Stat(filepath.Join(tmpDir, "custom", "renamed.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	_, err = os.Stat(filepath.Join(tmpDir, "skipped.txt"))
+	assert.True(t, os.IsNotExist(err), "files are skipped when their front matter condition is not met")
+
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "with_partials.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "generated for foo\nGENERATED FOR FOO\nend of foo\n", string(content), "partials are available to templates")
+	_, err = os.Stat(filepath.Join(tmpDir, "partials"))
+	assert.True(t, os.IsNotExist(err), "partials are not copied to the output")
+
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "chart", "values.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image: {{ .Values.image }}\n", string(content), "files without the template extension are copied as is in strict mode")
+}
+
+func TestGenerateModulesWithConditions(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	userFile := filepath.Join(tmpDir, "ignored", "user_file.txt")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(userFile), 0755))
+	assert.NoError(t, ioutil.WriteFile(userFile, []byte("created by the user"), 0644))
+
+	conditions := []projectconfig.Condition{{
+		Action:     projectconfig.ActionIgnoreFile,
+		Expression: projectconfig.Expression{MatchField: "test", WhenValue: "bar"},
+		Data:       []string{"ignored/**"},
+	}}
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"test": "bar"}, tmpDir, "github.com/fake-org/repo-foo", baseTestFixturesDir, []string{}, conditions),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+
+	content, err := ioutil.ReadFile(userFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "created by the user", string(content), "ignored files should not be rendered, even when overwriting")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "file_to_template.txt"))
+	assert.NoError(t, err)
+}
+
+func TestGenerateModulesWithConflictingPaths(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("conflicting"), map[string]string{
+		"{{ .Params.a }}.txt": "a",
+		"{{ .Params.b }}.txt": "b",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"a": "same", "b": "same"}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.EqualError(t, err, generate.RenderErrors{errors.New("conflicting: {{ .Params.a }}.txt and {{ .Params.b }}.txt both render to same.txt")}.Error())
+}
+
+func TestGenerateModulesWithConflictingOutputs(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("conflicting", "strictMode: true"), map[string]string{
+		"a.yaml":      "a",
+		"a.tmpl.yaml": "a",
+		"b.tmpl.txt":  "---zero\npath: c.txt\n---\nb",
+		"c.tmpl.txt":  "c",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	renderErrors, ok := err.(generate.RenderErrors)
+	assert.True(t, ok, "all the conflicts should be returned")
+	assert.Len(t, renderErrors, 2)
+	assert.Contains(t, err.Error(), "both render to a.yaml")
+	assert.Contains(t, err.Error(), "b.tmpl.txt and c.tmpl.txt both render to c.txt", "front matter paths should be checked")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "c.txt"))
+	assert.True(t, os.IsNotExist(err), "no files should be written when files conflict")
+}
+
+func TestGenerateModulesWithRenamedTemplates(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("renamed", "strictMode: true"), map[string]string{
+		"config.tmpl": "name: {{ .Name }}",
+		"static.txt":  "{{ kept }}",
+	})
+	defer removeModule()
+
+	conditions := []projectconfig.Condition{
+		{
+			Action:     projectconfig.ActionRenameFile,
+			Expression: projectconfig.Expression{MatchField: "rename", WhenValue: "yes"},
+			Data:       []string{"config.tmpl", "config"},
+		},
+		{
+			Action:     projectconfig.ActionRenameFile,
+			Expression: projectconfig.Expression{MatchField: "rename", WhenValue: "yes"},
+			Data:       []string{"static.txt", "static.tmpl.txt"},
+		},
+	}
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"rename": "yes"}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, conditions),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "config"))
+	assert.NoError(t, err)
+	assert.Equal(t, "name: foo", string(content), "files with the template extension in the module should be rendered when renamed")
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "static.tmpl.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{{ kept }}", string(content), "files renamed to the template extension should be copied as is")
+
+	config, err := module.ParseModuleConfig(moduleDir)
+	assert.NoError(t, err)
+	templates, err := generate.ModuleTemplates(moduleDir, config)
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+	assert.Equal(t, "config.tmpl", templates[0].Source, "the templates linted should be the ones rendered")
+}
+
+func TestGenerateModulesWithCRLFFrontMatter(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("crlf"), map[string]string{
+		"run.sh": "---zero\r\nmode: \"0755\"\r\n---\r\nbody\r\n",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "run.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, "body\r\n", string(content), "front matter with windows line endings should be stripped")
+	info, err := os.Stat(filepath.Join(tmpDir, "run.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestGenerateModulesWithMissingKeysInPaths(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("missing-paths"), map[string]string{
+		"{{ .Params.service }}/README.md":            "service",
+		"{{ .Params.name }}/README.md":               "name",
+		"{{ .Params.oops }}/{{ .Params.oops2 }}.txt": "{{ .Params.name }}",
+		"{{ .ProjectParams.docs }}/README.md":        "docs",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"name": "api"}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+	files, err := ioutil.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1, "files with a missing key in their path should be skipped")
+	assert.Equal(t, "api", files[0].Name())
+
+	assert.NoError(t, os.RemoveAll(tmpDir))
+	_, err = generate.Generate(projectConfig, true, true)
+	renderErrors, ok := err.(generate.RenderErrors)
+	assert.True(t, ok, "all the path errors should be returned")
+	assert.Len(t, renderErrors, 4)
+	assert.Contains(t, err.Error(), "template: {{ .Params.service }}/README.md:1: undefined key .Params.service")
+	assert.Contains(t, err.Error(), "undefined key .ProjectParams.docs")
+	assert.Contains(t, err.Error(), "undefined key .Params.oops2")
+}
+
+func TestGenerateModulesWithTemplateErrors(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("broken"), map[string]string{
+		"valid.txt":        "{{ .Name }}",
+		"unclosed.txt":     "line\n{{ if .Name }}",
+		"front_matter.txt": "---zero\nvars:\n  a: b\n---\n\n{{ .Name.Missing }}",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+
+	renderErrors, ok := err.(generate.RenderErrors)
+	assert.True(t, ok, "all the template errors should be returned")
+	assert.Len(t, renderErrors, 2)
+	assert.Contains(t, err.Error(), "template: unclosed.txt:2")
+	assert.Contains(t, err.Error(), "template: front_matter.txt:6", "lines should include the front matter")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "valid.txt"))
+	assert.True(t, os.IsNotExist(err), "no files should be written when a template fails")
+}
+
+func TestGenerateModulesWithMissingKeys(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("missing", "missingKey: zero"), map[string]string{
+		"config.yml": "name: {{ .Params.name }}\nregion: {{ .Params.regoin }}\nhost: {{ .Params.db.hots }}\n",
+		"project.yml": "cache: {{ .Params.cache.host }}\ndomain: {{ .ProjectParams.domain }}\n" +
+			"self: {{ .Modules.mod1.Params.zz }}\nother: {{ .Modules.be.Params.zz }}\n",
+	})
+	defer removeModule()
+
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: "foo",
+		Modules: projectconfig.Modules{
+			"mod1": projectconfig.NewModule(projectconfig.Parameters{"name": "foo", "db": map[string]interface{}{"host": "localhost"}}, tmpDir, "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{}),
+		},
+	}
+
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "config.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "name: foo\nregion: \nhost: \n", string(content), "missing parameters should render as empty strings")
+	content, err = ioutil.ReadFile(filepath.Join(tmpDir, "project.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cache: \ndomain: \nself: \nother: \n", string(content), "keys missing from any parameters, or their parents, should render as empty strings")
+
+	assert.NoError(t, os.RemoveAll(tmpDir))
+	_, err = generate.Generate(projectConfig, true, true)
+	renderErrors, ok := err.(generate.RenderErrors)
+	assert.True(t, ok)
+	assert.Len(t, renderErrors, 6, "each undefined key should be reported")
+	assert.Contains(t, err.Error(), "template: config.yml:2: undefined key .Params.regoin")
+	assert.Contains(t, err.Error(), "template: config.yml:3: undefined key .Params.db.hots")
+	assert.Contains(t, err.Error(), "template: project.yml:1: undefined key .Params.cache.host")
+	assert.Contains(t, err.Error(), "template: project.yml:4: undefined key .Modules.be.Params.zz")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "config.yml"))
+	assert.True(t, os.IsNotExist(err), "strict mode should fail on undefined keys")
+}
+
+func TestGenerateModulesWithProjectData(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	template := `repo: {{ .Modules.backend.Files.Repository }}
+api: {{ .Modules.backend.Params.apiHost }}
+secret: {{ index .Modules.backend.Params "apiToken" }}
+domain: {{ .ProjectParams.domain }}
+environments: {{ join "," .Environments }}
+zero: {{ .ZeroVersion }}
+module: {{ .Module.Key }} {{ .Module.Name }} {{ .Module.Source }}
+revision: [{{ .Module.Revision }}]
+`
+	moduleDir, removeModule := writeModule(t, moduleConfig("frontend"), map[string]string{"config.yml": template})
+	defer removeModule()
+
+	backend := projectconfig.NewModule(projectconfig.Parameters{"apiHost": "api.example.com", "apiToken": "s3cr3t"}, filepath.Join(tmpDir, "backend"), "github.com/fake-org/backend", moduleDir, []string{}, []projectconfig.Condition{})
+	backend.Secrets = projectconfig.Parameters{"apiToken": "s3cr3t"}
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name:         "foo",
+		Parameters:   projectconfig.Parameters{"domain": "example.com"},
+		Environments: []string{"staging", "production"},
+		Modules: projectconfig.Modules{
+			"frontend": projectconfig.NewModule(projectconfig.Parameters{}, filepath.Join(tmpDir, "frontend"), "github.com/fake-org/frontend", moduleDir, []string{}, []projectconfig.Condition{}),
+			"backend":  backend,
+		},
+	}
+	_, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(tmpDir, "frontend", "config.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, `repo: github.com/fake-org/backend
+api: api.example.com
+secret: <no value>
+domain: example.com
+environments: staging,production
+zero: SNAPSHOT
+module: frontend frontend `+moduleDir+`
+revision: []
+`, string(content), "secrets of other modules should not be available")
+}
+
+func TestGenerateModulesManifest(t *testing.T) {
+	teardown, tmpDir := setupTeardown(t)
+	defer teardown(t)
+
+	moduleDir, removeModule := writeModule(t, moduleConfig("manifest"), map[string]string{
+		"name.txt":   "{{ .Params.name }}",
+		"bin/run.sh": "#!/bin/sh\n",
+	})
+	defer removeModule()
+	assert.NoError(t, os.Chmod(filepath.Join(moduleDir, "templates", "bin", "run.sh"), 0755))
+
+	mod := projectconfig.NewModule(projectconfig.Parameters{"name": "foo", "token": "s3cr3t"}, filepath.Join(tmpDir, "mod1"), "github.com/fake-org/repo-foo", moduleDir, []string{}, []projectconfig.Condition{})
+	mod.Secrets = projectconfig.Parameters{"token": "s3cr3t"}
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name:    "foo",
+		Modules: projectconfig.Modules{"mod1": mod},
+	}
+	manifest, err := generate.Generate(projectConfig, true, false)
+	assert.NoError(t, err)
+
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Output < manifest.Files[j].Output })
+	assert.Equal(t, []generate.ManifestEntry{
+		{
+			Module:      "mod1",
+			Template:    "bin/run.sh",
+			Output:      filepath.ToSlash(filepath.Join(tmpDir, "mod1", "bin", "run.sh")),
+			Mode:        "0755",
+			ContentHash: "sha256:a8076d3d28d21e02012b20eaf7dbf75409a6277134439025f282e368e3305abf",
+			ParamsHash:  "sha256:5dca85e76989e55ebbdec9e5304832c06a9ead7138b04372c65553003cfd2849", // secrets are left out
+		},
+		{
+			Module:      "mod1",
+			Template:    "name.txt",
+			Output:      filepath.ToSlash(filepath.Join(tmpDir, "mod1", "name.txt")),
+			Mode:        "0644",
+			ContentHash: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			ParamsHash:  "sha256:5dca85e76989e55ebbdec9e5304832c06a9ead7138b04372c65553003cfd2849",
+		},
+	}, manifest.Files)
+
+	assert.NoError(t, generate.WriteManifest(tmpDir, manifest))
+	written, err := generate.ReadManifest(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mod1/bin/run.sh", "mod1/name.txt"}, []string{written.Files[0].Output, written.Files[1].Output}, "outputs should be relative to the project")
+
+	// regenerating a single file keeps the entries of the other files
+	changed := generate.Manifest{ZeroVersion: manifest.ZeroVersion, Files: []generate.ManifestEntry{manifest.Files[1]}}
+	changed.Files[0].ContentHash = "sha256:changed"
+	assert.NoError(t, generate.WriteManifest(tmpDir, changed))
+
+	rewritten, err := generate.ReadManifest(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, rewritten.Files, 2)
+	assert.Equal(t, written.Files[0], rewritten.Files[0])
+	assert.Equal(t, "mod1/name.txt", rewritten.Files[1].Output)
+	assert.Equal(t, "sha256:changed", rewritten.Files[1].ContentHash)
 }
@@ -0,0 +1,136 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/pkg/util/fs"
+)
+
+// Manifest records the files written by Generate, it is stored in the project as constants.ZeroManifestFile
+type Manifest struct {
+	ZeroVersion string          `json:"zeroVersion"`
+	Files       []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes how a file of the project was generated
+type ManifestEntry struct {
+	// Module is the name of the module in the project file
+	Module string `json:"module"`
+	// Revision of the module's source, see module.GetSourceRevision
+	Revision string `json:"revision,omitempty"`
+	// Template is the path of the file in the module, relative to its inputDir
+	Template string `json:"template"`
+	// Output is the path of the generated file, WriteManifest stores it relative to the project
+	Output string `json:"output"`
+	// Mode of the generated file in octal, eg: 0644
+	Mode string `json:"mode"`
+	// ContentHash is the sha256 of the generated file
+	ContentHash string `json:"contentHash"`
+	// ParamsHash is the sha256 of the module's parameters without its secrets, so the hash can't reveal them
+	ParamsHash string `json:"paramsHash"`
+}
+
+// hashFile returns the sha256 of a file as `sha256:<hex>`
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashParameters returns the sha256 of the parameters encoded as json, keys are sorted by the encoding
+func hashParameters(params projectconfig.Parameters) (string, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// relativeOutput returns the path of a generated file relative to the project in dir, with forward slashes
+func relativeOutput(dir string, output string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absOutput, err := filepath.Abs(filepath.FromSlash(output))
+	if err != nil {
+		return "", err
+	}
+	relative, err := filepath.Rel(absDir, absOutput)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+// ReadManifest reads the manifest of the project in dir, it returns an empty manifest when the project has none
+func ReadManifest(dir string) (Manifest, error) {
+	manifest := Manifest{Files: []ManifestEntry{}}
+	content, err := ioutil.ReadFile(filepath.Join(dir, constants.ZeroManifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("%s is invalid: %v", constants.ZeroManifestFile, err)
+	}
+	return manifest, nil
+}
+
+// WriteManifest records the files of the manifest in the project in dir,
+// the entries of files that were not generated this time, eg: existing files that were not overwritten, are kept.
+// Outputs are stored relative to dir so the manifest doesn't depend on where the project is
+func WriteManifest(dir string, manifest Manifest) error {
+	previous, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	files := []ManifestEntry{}
+	for _, entry := range manifest.Files {
+		if entry.Output, err = relativeOutput(dir, entry.Output); err != nil {
+			return err
+		}
+		written[entry.Output] = true
+		files = append(files, entry)
+	}
+	for _, entry := range previous.Files {
+		if !written[entry.Output] {
+			files = append(files, entry)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Output < files[j].Output
+	})
+	manifest.Files = files
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(dir, constants.ZeroManifestFile)
+	if err := fs.CreateDirs(filepath.Dir(manifestPath)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifestPath, append(content, '\n'), 0644)
+}
@@ -0,0 +1,98 @@
+package generate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+)
+
+// ModuleTemplate is a file of a module that Generate renders as a template, it is used to analyze modules without rendering them
+type ModuleTemplate struct {
+	// Source is the path of the file, relative to the module's inputDir or partialsDir for partials
+	Source  string
+	Partial bool
+	// Body is the template without its front matter
+	Body string
+	// FrontMatterLines is the number of lines of the front matter, to report lines of the body as lines of the file
+	FrontMatterLines int
+	LeftDelim        string
+	RightDelim       string
+	// Condition of the front matter, if any
+	Condition *projectconfig.Expression
+}
+
+// ModuleTemplates returns the templates and partials of a module with the delimiters they are rendered with
+func ModuleTemplates(moduleRoot string, moduleConfig moduleconfig.ModuleConfig) ([]ModuleTemplate, error) {
+	leftDelim, rightDelim := getDelimiters(moduleConfig.Delimiters)
+	templates := []ModuleTemplate{}
+
+	partialsDir := ""
+	if moduleConfig.PartialsDir != "" {
+		partialsDir = path.Join(moduleRoot, moduleConfig.PartialsDir)
+		paths, err := getAllFilePathsInDirectory(partialsDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, partialPath := range paths {
+			content, err := ioutil.ReadFile(partialPath)
+			if err != nil {
+				return nil, err
+			}
+			name, err := filepath.Rel(partialsDir, partialPath)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, ModuleTemplate{
+				Source:     filepath.ToSlash(name),
+				Partial:    true,
+				Body:       string(content),
+				LeftDelim:  leftDelim,
+				RightDelim: rightDelim,
+			})
+		}
+	}
+
+	moduleDir := path.Join(moduleRoot, moduleConfig.InputDir)
+	files, err := getModuleFiles(moduleDir, partialsDir, projectconfig.Module{})
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		isTemplate, err := isTemplateFile(moduleDir, file, moduleConfig.StrictMode)
+		if err != nil {
+			return nil, err
+		}
+		if !isTemplate {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(moduleDir, filepath.FromSlash(file.Source)))
+		if err != nil {
+			return nil, err
+		}
+		fm, body, err := splitFrontMatter(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file.Source, err)
+		}
+		template := ModuleTemplate{
+			Source:           file.Source,
+			Body:             string(body),
+			FrontMatterLines: strings.Count(string(content[:len(content)-len(body)]), "\n"),
+			LeftDelim:        leftDelim,
+			RightDelim:       rightDelim,
+		}
+		if fm != nil {
+			template.Condition = fm.Condition
+			if len(fm.Delimiters) == 2 {
+				template.LeftDelim, template.RightDelim = fm.Delimiters[0], fm.Delimiters[1]
+			}
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
@@ -0,0 +1,188 @@
+package golden
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
+	"github.com/commitdev/zero/internal/generate"
+	"github.com/google/go-cmp/cmp"
+	"github.com/termie/go-shutil"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// TestsDir is the directory of the module's fixtures
+	TestsDir = "tests"
+	// FixtureExtension is the suffix of fixture files, the rest of the file name is the name of the fixture
+	FixtureExtension = ".params.yml"
+	// ExpectedDir is the directory of a fixture's golden output, eg: tests/<fixture>/expected
+	ExpectedDir = "expected"
+)
+
+// DefaultProjectName is the name of the project used when rendering a fixture that doesn't set it
+const DefaultProjectName = "test-project"
+
+// Fixture is the values a module is rendered with, read from tests/<name>.params.yml
+type Fixture struct {
+	Name        string                   `yaml:"-"`
+	ProjectName string                   `yaml:"name,omitempty"`
+	Repository  string                   `yaml:"repository,omitempty"`
+	Parameters  projectconfig.Parameters `yaml:"parameters,omitempty"`
+	// Conditions replace the module's conditions when set
+	Conditions []projectconfig.Condition `yaml:"conditions,omitempty"`
+}
+
+// Result is the outcome of rendering a fixture, differences are empty when the output matches the golden files
+type Result struct {
+	Fixture     string
+	Differences []string
+	Updated     bool
+}
+
+// Passed returns whether the output matched the golden files
+func (r Result) Passed() bool {
+	return len(r.Differences) == 0
+}
+
+// LoadFixtures reads the fixtures of the module, sorted by name
+func LoadFixtures(moduleDir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(moduleDir, TestsDir, "*"+FixtureExtension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := []Fixture{}
+	for _, fixturePath := range paths {
+		data, err := ioutil.ReadFile(fixturePath)
+		if err != nil {
+			return nil, err
+		}
+		fixture := Fixture{}
+		if err := yaml.UnmarshalStrict(data, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %v", fixturePath, err)
+		}
+		for i, condition := range fixture.Conditions {
+			if err := condition.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid fixture %s: conditions[%d]: %v", fixturePath, i, err)
+			}
+		}
+		fixture.Name = strings.TrimSuffix(filepath.Base(fixturePath), FixtureExtension)
+		if fixture.ProjectName == "" {
+			fixture.ProjectName = DefaultProjectName
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// Run renders the module with the fixture's values, the same way `zero create` does, into a temporary directory
+// and compares it to the golden files in tests/<fixture>/expected. With update the golden files are replaced instead
+func Run(moduleDir string, moduleConfig moduleconfig.ModuleConfig, fixture Fixture, update bool) (Result, error) {
+	result := Result{Fixture: fixture.Name}
+
+	outputDir, err := ioutil.TempDir("", "zero-module-test")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(outputDir)
+
+	conditions := fixture.Conditions
+	if conditions == nil {
+		conditions = moduleconfig.SummarizeConditions(moduleConfig)
+	}
+	projectConfig := projectconfig.ZeroProjectConfig{
+		Name: fixture.ProjectName,
+		Modules: projectconfig.Modules{
+			moduleConfig.Name: projectconfig.NewModule(fixture.Parameters, outputDir, fixture.Repository, moduleDir, moduleConfig.DependsOn, conditions),
+		},
+	}
+	if _, err := generate.Generate(projectConfig, true, false); err != nil {
+		return result, fmt.Errorf("fixture %s: %v", fixture.Name, err)
+	}
+
+	expectedDir := filepath.Join(moduleDir, TestsDir, fixture.Name, ExpectedDir)
+	if update {
+		if err := os.RemoveAll(expectedDir); err != nil {
+			return result, err
+		}
+		if err := os.MkdirAll(filepath.Dir(expectedDir), os.ModePerm); err != nil {
+			return result, err
+		}
+		result.Updated = true
+		return result, shutil.CopyTree(outputDir, expectedDir, nil)
+	}
+
+	result.Differences, err = Compare(expectedDir, outputDir)
+	return result, err
+}
+
+// Compare returns the differences between the files of the expected and actual directories
+func Compare(expectedDir string, actualDir string) ([]string, error) {
+	expected, err := readFiles(expectedDir)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := readFiles(actualDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for path := range expected {
+		paths = append(paths, path)
+	}
+	for path := range actual {
+		if _, ok := expected[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	differences := []string{}
+	for _, path := range paths {
+		expectedContent, isExpected := expected[path]
+		actualContent, isActual := actual[path]
+		switch {
+		case !isActual:
+			differences = append(differences, fmt.Sprintf("%s: missing", path))
+		case !isExpected:
+			differences = append(differences, fmt.Sprintf("%s: unexpected file", path))
+		case expectedContent != actualContent:
+			diff := cmp.Diff(strings.Split(expectedContent, "\n"), strings.Split(actualContent, "\n"))
+			differences = append(differences, fmt.Sprintf("%s: content differs (-expected +actual):\n%s", path, diff))
+		}
+	}
+	return differences, nil
+}
+
+// readFiles returns the content of the files of dir keyed by their slash separated relative path,
+// a missing directory has no files
+func readFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relativePath)] = string(content)
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,91 @@
+package golden_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/golden"
+	"github.com/commitdev/zero/internal/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/termie/go-shutil"
+)
+
+func setupModule(t *testing.T) string {
+	tmpDir, err := ioutil.TempDir("", "golden")
+	assert.NoError(t, err)
+	moduleDir := filepath.Join(tmpDir, "module")
+	assert.NoError(t, shutil.CopyTree("../../tests/test_data/module-test", moduleDir, nil))
+	return moduleDir
+}
+
+func TestModuleFixtures(t *testing.T) {
+	moduleDir := setupModule(t)
+	defer os.RemoveAll(filepath.Dir(moduleDir))
+
+	moduleConfig, err := module.ParseModuleConfig(moduleDir)
+	assert.NoError(t, err)
+
+	fixtures, err := golden.LoadFixtures(moduleDir)
+	assert.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+	assert.Equal(t, "default", fixtures[0].Name)
+	assert.Equal(t, "acme", fixtures[0].ProjectName)
+	assert.Equal(t, golden.DefaultProjectName, fixtures[1].ProjectName)
+
+	t.Run("Output matching the golden files passes", func(t *testing.T) {
+		for _, fixture := range fixtures {
+			result, err := golden.Run(moduleDir, moduleConfig, fixture, false)
+			assert.NoError(t, err)
+			assert.True(t, result.Passed(), "%s: %v", fixture.Name, result.Differences)
+		}
+	})
+
+	t.Run("Differences are reported per file", func(t *testing.T) {
+		fixture := fixtures[1]
+		fixture.Parameters = fixtures[0].Parameters
+		result, err := golden.Run(moduleDir, moduleConfig, fixture, false)
+		assert.NoError(t, err)
+		assert.False(t, result.Passed())
+		assert.Len(t, result.Differences, 2)
+		assert.Contains(t, result.Differences[0], "README.md: content differs")
+		assert.Contains(t, result.Differences[0], "example.com")
+		assert.Equal(t, "monitoring/alerts.yml: unexpected file", result.Differences[1])
+	})
+
+	t.Run("Update replaces the golden files", func(t *testing.T) {
+		fixture := fixtures[1]
+		fixture.ProjectName = "renamed"
+		result, err := golden.Run(moduleDir, moduleConfig, fixture, true)
+		assert.NoError(t, err)
+		assert.True(t, result.Updated)
+
+		content, err := ioutil.ReadFile(filepath.Join(moduleDir, "tests/no-monitoring/expected/README.md"))
+		assert.NoError(t, err)
+		assert.Equal(t, "# renamed\nServed from example.org\n", string(content))
+
+		result, err = golden.Run(moduleDir, moduleConfig, fixture, false)
+		assert.NoError(t, err)
+		assert.True(t, result.Passed())
+	})
+}
+
+func TestLoadFixturesWithInvalidCondition(t *testing.T) {
+	moduleDir := setupModule(t)
+	defer os.RemoveAll(filepath.Dir(moduleDir))
+
+	content := `conditions:
+  - action: renameFile
+    matchField: monitoring
+    whenValue: "yes"
+    data:
+      - alerts.yml
+`
+	fixturePath := filepath.Join(moduleDir, golden.TestsDir, "broken"+golden.FixtureExtension)
+	assert.NoError(t, ioutil.WriteFile(fixturePath, []byte(content), 0644))
+
+	_, err := golden.LoadFixtures(moduleDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "conditions[0]: renameFile requires data: [<path>, <new path>]")
+}
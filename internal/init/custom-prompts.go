@@ -41,7 +41,7 @@ func promptAWSProfilePicker(params map[string]string) error {
 		Condition: NoCondition,
 		Validate:  NoValidation,
 	}
-	_, value := promptParameter(awsPrompt)
+	_, value := promptParameter(awsPrompt, params)
 	credErr := project.FillAWSProfile("", value, params)
 	if credErr != nil {
 		return errors.New("Failed to retrieve profile, falling back to User input")
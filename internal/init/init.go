@@ -51,7 +51,7 @@ func Init(outDir string, localModulePath string) *projectconfig.ZeroProjectConfi
 	// Prompting for push-up stream, then conditionally prompting for github
 	prompts["GithubRootOrg"].RunPrompt(initParams, emptyEnvVarTranslationMap)
 
-	projectData := promptAllModules(moduleConfigs)
+	projectData := promptAllModules(moduleConfigs, projectConfig.Name)
 
 	// Map parameter values back to specific modules
 	for moduleName, module := range moduleConfigs {
@@ -61,7 +61,7 @@ func Init(outDir string, localModulePath string) *projectconfig.ZeroProjectConfi
 		projectModuleParams := moduleconfig.SummarizeParameters(module, projectData)
 		projectModuleConditions := moduleconfig.SummarizeConditions(module)
 
-		projectConfig.Modules[moduleName] = projectconfig.NewModule(
+		projectModule := projectconfig.NewModule(
 			projectModuleParams,
 			repoName,
 			repoURL,
@@ -69,6 +69,11 @@ func Init(outDir string, localModulePath string) *projectconfig.ZeroProjectConfi
 			module.DependsOn,
 			projectModuleConditions,
 		)
+		projectModule.Secrets = moduleconfig.SummarizeSecrets(module, projectData)
+		for field, value := range moduleconfig.SummarizeCredentials(module, projectData) {
+			projectModule.Secrets[field] = value
+		}
+		projectConfig.Modules[moduleName] = projectModule
 	}
 
 	return &projectConfig
@@ -186,7 +191,7 @@ func chooseStack(reg registry.Registry) []string {
 func defaultProjConfig() projectconfig.ZeroProjectConfig {
 	return projectconfig.ZeroProjectConfig{
 		Name:       "",
-		Parameters: map[string]string{},
+		Parameters: projectconfig.Parameters{},
 		Modules:    projectconfig.Modules{},
 	}
 }
@@ -7,9 +7,13 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/commitdev/zero/internal/condition"
 	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/config/projectconfig"
 	"github.com/commitdev/zero/internal/constants"
 	"github.com/commitdev/zero/internal/util"
 	"github.com/commitdev/zero/pkg/util/exit"
@@ -107,7 +111,9 @@ func ValidateProjectName(input string) error {
 // 1. Execute (this could potentially be refactored into type + data)
 // 2. type: specific ways of obtaining values (in AWS credential case it will set 2 values to the map)
 // 3. value: directly assigns a value to a parameter
-// 4. prompt: requires users to select an option OR input a string
+// 4. prompt: requires users to select an option OR input a value of the parameter's type
+// Typed values are stored in their string form (see projectconfig.FormatValue) so they can be passed as env vars,
+// they are converted back to their type when summarized into the project config
 func (p PromptHandler) RunPrompt(projectParams map[string]string, envVarTranslationMap map[string]string) error {
 	var err error
 	var result string
@@ -122,27 +128,39 @@ func (p PromptHandler) RunPrompt(projectParams map[string]string, envVarTranslat
 		// it wouldnt leak things the module shouldnt have access to
 		if p.Parameter.Execute != "" {
 			result = executeCmd(p.Parameter.Execute, projectParams, envVarTranslationMap)
-		} else if p.Parameter.Type != "" {
+		} else if !p.Parameter.IsValueType() {
 			err = CustomPromptHandler(p.Parameter.Type, projectParams)
 		} else if p.Parameter.Value != "" {
 			result = p.Parameter.Value
 		} else {
-			err, result = promptParameter(p)
+			err, result = promptParameter(p, projectParams)
 		}
 		if err != nil {
 			return err
 		}
 
+		value, err := p.Parameter.ParseValue(result)
+		if err != nil {
+			return err
+		}
 		// Append the result to parameter map
-		projectParams[p.Field] = sanitizeParameterValue(result)
+		projectParams[p.Field] = projectconfig.FormatValue(value)
 	} else {
 		flog.Debugf("Skipping prompt(%s) due to condition failed", p.Field)
 	}
 	return nil
 }
 
-func promptParameter(prompt PromptHandler) (error, string) {
+// promptParameter prompts for the value of a parameter, Validate runs on every keystroke.
+// A function fieldValidation runs a command so it only checks the submitted value, with the values prompted so far as env vars
+func promptParameter(prompt PromptHandler, projectParams map[string]string) (error, string) {
 	param := prompt.Parameter
+	var validateSubmitted func(string) error
+	if param.FieldValidation.Type == constants.FunctionValidation {
+		validateSubmitted = func(input string) error {
+			return param.ValidateValue(input, projectParams)
+		}
+	}
 	label := param.Label
 	if param.Label == "" {
 		label = param.Field
@@ -151,7 +169,12 @@ func promptParameter(prompt PromptHandler) (error, string) {
 
 	var err error
 	var result string
-	if len(param.Options) > 0 {
+	switch {
+	case param.Type == moduleconfig.TypeBool && len(param.Options) == 0:
+		result, err = promptBool(label, defaultValue)
+	case param.Type == moduleconfig.TypeList || param.Type == moduleconfig.TypeMap:
+		result, err = promptEntries(prompt, label, validateSubmitted)
+	case len(param.Options) > 0:
 		var selectedIndex int
 		// Scope of selected does not have the label data, so we need a dynamic
 		// template with string format to put in the label in `selected`
@@ -169,15 +192,23 @@ func promptParameter(prompt PromptHandler) (error, string) {
 		}
 
 		selectedIndex, _, err = prompt.Run()
-		result = param.Options[selectedIndex].Key.(string)
-	} else {
-		prompt := promptui.Prompt{
-			Label:     label,
-			Default:   defaultValue,
-			AllowEdit: true,
-			Validate:  prompt.Validate,
+		result = fmt.Sprintf("%v", param.Options[selectedIndex].Key)
+	default:
+		validate := typedValidation(param, prompt.Validate)
+		for {
+			prompt := promptui.Prompt{
+				Label:     label,
+				Default:   defaultValue,
+				AllowEdit: true,
+				Validate:  validate,
+			}
+			result, err = prompt.Run()
+			if err != nil || isValidSubmission(validateSubmitted, result) {
+				break
+			}
+			// the rejected value can be edited on the next attempt
+			defaultValue = result
 		}
-		result, err = prompt.Run()
 	}
 	if err != nil {
 		return err, ""
@@ -200,39 +231,129 @@ func executeCmd(command string, envVars map[string]string, envVarTranslationMap
 	return string(out)
 }
 
-// aws cli prints output with linebreak in them
-func sanitizeParameterValue(str string) string {
-	re := regexp.MustCompile("\\n")
-	return re.ReplaceAllString(str, "")
+// promptBool asks a yes/no question, the default is listed first
+func promptBool(label string, defaultValue string) (string, error) {
+	items := []string{"yes", "no"}
+	if isTrue, err := strconv.ParseBool(defaultValue); err == nil && !isTrue {
+		items = []string{"no", "yes"}
+	}
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+	}
+	_, result, err := prompt.Run()
+	return result, err
+}
+
+// promptEntries prompts for the items of a list or map parameter one at a time until an empty entry,
+// map entries are entered as key=value. The default is used when no entry is given.
+func promptEntries(handler PromptHandler, label string, validateSubmitted func(string) error) (string, error) {
+	param := handler.Parameter
+	hint := "item"
+	if param.Type == moduleconfig.TypeMap {
+		hint = "key=value"
+	}
+
+	entries := []string{}
+	for {
+		prompt := promptui.Prompt{
+			Label: fmt.Sprintf("%s (%s %d, leave empty to finish)", label, hint, len(entries)+1),
+			Validate: func(input string) error {
+				if input == "" {
+					return nil
+				}
+				if param.Type == moduleconfig.TypeMap && !strings.Contains(input, "=") {
+					return errors.New("Entries must be in the format key=value")
+				}
+				if handler.Validate != nil {
+					return handler.Validate(input)
+				}
+				return nil
+			},
+		}
+		entry, err := prompt.Run()
+		if err != nil {
+			return "", err
+		}
+		if entry == "" {
+			break
+		}
+		if !isValidSubmission(validateSubmitted, entry) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return param.Default, nil
+	}
+	value, err := param.ParseValue(strings.Join(entries, "\n"))
+	if err != nil {
+		return "", err
+	}
+	return projectconfig.FormatValue(value), nil
+}
+
+// isValidSubmission runs the validation of a submitted value, the reason it is invalid is shown to the user
+func isValidSubmission(validate func(string) error, value string) bool {
+	if validate == nil {
+		return true
+	}
+	if err := validate(value); err != nil {
+		flog.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
+// keystrokeValidation returns the parameter's fieldValidation to run on every keystroke,
+// function validations run a command so they are left to promptParameter, which checks the submitted value
+func keystrokeValidation(parameter moduleconfig.Parameter) func(string) error {
+	if parameter.FieldValidation.Type == "" || parameter.FieldValidation.Type == constants.FunctionValidation {
+		return nil
+	}
+	return func(input string) error {
+		return parameter.ValidateValue(input, nil)
+	}
+}
+
+// typedValidation checks that the input can be converted to the parameter's type before the field validation
+func typedValidation(param moduleconfig.Parameter, validate func(string) error) func(string) error {
+	return func(input string) error {
+		if _, err := param.ParseValue(input); err != nil {
+			return err
+		}
+		if validate != nil {
+			return validate(input)
+		}
+		return nil
+	}
 }
 
 // PromptModuleParams renders series of prompt UI based on the config
 func PromptModuleParams(moduleConfig moduleconfig.ModuleConfig, parameters map[string]string) (map[string]string, error) {
 	envVarTranslationMap := moduleConfig.GetParamEnvVarTranslationMap()
-	for _, parameter := range moduleConfig.Parameters {
+	orderedParameters, err := moduleConfig.OrderedParameters()
+	if err != nil {
+		return parameters, err
+	}
+	for _, parameter := range orderedParameters {
 		// deduplicate fields already prompted and received
 		if _, isAlreadySet := parameters[parameter.Field]; isAlreadySet {
 			continue
 		}
 
-		var validateFunc func(input string) error = nil
-
-		// type:regex field validation for zero-module.yaml
-		if parameter.FieldValidation.Type == constants.RegexValidation {
-			validateFunc = func(input string) error {
-				var regexRule = regexp.MustCompile(parameter.FieldValidation.Value)
-				if !regexRule.MatchString(input) {
-					return errors.New(parameter.FieldValidation.ErrorMessage)
-				}
-				return nil
-			}
+		// templated default and value are rendered with the answers so far
+		parameter, err := parameter.RenderTemplates(parameters)
+		if err != nil {
+			return parameters, err
 		}
-		// TODO: type:fuction field validation for zero-module.yaml
 
+		// fieldValidation for zero-module.yaml
 		promptHandler := PromptHandler{
 			Parameter: parameter,
 			Condition: paramConditionsMapper(parameter.Conditions),
-			Validate:  validateFunc,
+			Validate:  keystrokeValidation(parameter),
 		}
 		// merging the context of param and credentals
 		// this treats credentialEnvs as throwaway, parameters is shared between modules
@@ -240,7 +361,7 @@ func PromptModuleParams(moduleConfig moduleconfig.ModuleConfig, parameters map[s
 		// for k, v := range parameters {
 		// 	credentialEnvs[k] = v
 		// }
-		err := promptHandler.RunPrompt(parameters, envVarTranslationMap)
+		err = promptHandler.RunPrompt(parameters, envVarTranslationMap)
 		if err != nil {
 			return parameters, err
 		}
@@ -252,19 +373,162 @@ func PromptModuleParams(moduleConfig moduleconfig.ModuleConfig, parameters map[s
 // promptAllModules takes a map of all the modules and prompts the user for values for all the parameters
 // Important: This is done here because in this step we share the parameter across modules,
 // meaning if module A and B both asks for region, it will reuse the response for both (and is deduped during runtime)
-func promptAllModules(modules map[string]moduleconfig.ModuleConfig) map[string]string {
-	parameterValues := map[string]string{}
-	for _, config := range modules {
-		var err error
+// The project name is available to the parameters as `projectName`, the modules are prompted in the order of OrderModules
+func promptAllModules(modules map[string]moduleconfig.ModuleConfig, projectName string) map[string]string {
+	moduleNames, err := OrderModules(modules)
+	if err != nil {
+		exit.Fatal("Exiting prompt:  %v\n", err)
+	}
 
+	parameterValues := map[string]string{"projectName": projectName}
+	for _, name := range moduleNames {
+		config := modules[name]
 		parameterValues, err = PromptModuleParams(config, parameterValues)
 		if err != nil {
 			exit.Fatal("Exiting prompt(%s):  %v\n", config.Name, err)
 		}
 	}
+
+	parameterValues, err = PromptCredentials(modules, parameterValues)
+	if err != nil {
+		exit.Fatal("Exiting prompt:  %v\n", err)
+	}
 	return parameterValues
 }
 
+// OrderModules returns the names of the modules in the order their parameters should be prompted: sorted by name,
+// except modules are moved after the modules declaring the parameters their templated defaults and values refer to
+func OrderModules(modules map[string]moduleconfig.ModuleConfig) ([]string, error) {
+	names := []string{}
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	declaredBy := map[string][]string{}
+	for _, name := range names {
+		for _, parameter := range modules[name].Parameters {
+			declaredBy[parameter.Field] = append(declaredBy[parameter.Field], name)
+		}
+	}
+	dependencies := map[string][]string{}
+	for _, name := range names {
+		for _, parameter := range modules[name].Parameters {
+			references, err := parameter.References()
+			if err != nil {
+				return nil, fmt.Errorf("module %s: %v", name, err)
+			}
+			for _, reference := range references {
+				// parameters declared by the module itself are ordered by OrderedParameters
+				if util.ItemInSlice(declaredBy[reference], name) {
+					continue
+				}
+				for _, other := range declaredBy[reference] {
+					if !util.ItemInSlice(dependencies[name], other) {
+						dependencies[name] = append(dependencies[name], other)
+					}
+				}
+			}
+		}
+	}
+
+	ordered := []string{}
+	placed := map[string]bool{}
+	for len(ordered) < len(names) {
+		progress := false
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies[name] {
+				if !placed[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				ordered = append(ordered, name)
+				placed[name] = true
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			cycle := []string{}
+			for _, name := range names {
+				if !placed[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, fmt.Errorf("modules reference each other's parameters in a cycle: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// getCredentialPrompts returns the prompts of the credentials required by the modules, grouped by vendor.
+// Each vendor is only prompted once even if several modules require it
+func getCredentialPrompts(modules map[string]moduleconfig.ModuleConfig) ([]CredentialPrompts, error) {
+	moduleNames := []string{}
+	for name := range modules {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+
+	providers := map[string]moduleconfig.CredentialProvider{}
+	vendors := []string{}
+	for _, name := range moduleNames {
+		for _, vendor := range modules[name].RequiredCredentials {
+			if _, ok := providers[vendor]; ok {
+				continue
+			}
+			provider, err := modules[name].GetCredentialProvider(vendor)
+			if err != nil {
+				return nil, fmt.Errorf("module %s: %v", name, err)
+			}
+			providers[vendor] = provider
+			vendors = append(vendors, vendor)
+		}
+	}
+	sort.Strings(vendors)
+
+	credentialPrompts := []CredentialPrompts{}
+	for _, vendor := range vendors {
+		prompts := []PromptHandler{}
+		for _, field := range providers[vendor].Fields {
+			parameter := field.Parameter()
+			prompts = append(prompts, PromptHandler{
+				Parameter: parameter,
+				Condition: NoCondition,
+				Validate:  keystrokeValidation(parameter),
+			})
+		}
+		credentialPrompts = append(credentialPrompts, CredentialPrompts{Vendor: vendor, Prompts: prompts})
+	}
+	return credentialPrompts, nil
+}
+
+// PromptCredentials prompts for the credentials required by the modules that are not already in parameters,
+// eg: AWS keys picked from a profile by a module's AWSProfilePicker parameter are not asked again
+func PromptCredentials(modules map[string]moduleconfig.ModuleConfig, parameters map[string]string) (map[string]string, error) {
+	credentialPrompts, err := getCredentialPrompts(modules)
+	if err != nil {
+		return parameters, err
+	}
+	for _, vendorPrompts := range credentialPrompts {
+		for _, prompt := range vendorPrompts.Prompts {
+			if value, isAlreadySet := parameters[prompt.Field]; isAlreadySet && value != "" {
+				continue
+			}
+			if err := prompt.RunPrompt(parameters, map[string]string{}); err != nil {
+				return parameters, fmt.Errorf("%s credentials: %v", vendorPrompts.Vendor, err)
+			}
+		}
+	}
+	return parameters, nil
+}
+
 func paramConditionsMapper(conditions []moduleconfig.Condition) CustomConditionSignature {
 	if len(conditions) == 0 {
 		return NoCondition
@@ -273,8 +537,8 @@ func paramConditionsMapper(conditions []moduleconfig.Condition) CustomConditionS
 			// Prompts must pass every condition to proceed
 			for i := 0; i < len(conditions); i++ {
 				cond := conditions[i]
-				if !conditionHandler(cond)(params) {
-					flog.Debugf("Did not meet condition %v, expected %v to be %v", cond.Action, cond.MatchField, cond.WhenValue)
+				if !condition.Evaluate(cond.Expression, params) {
+					flog.Debugf("Did not meet condition %v: %v", cond.Action, cond.Expression)
 					return false
 				}
 			}
@@ -282,14 +546,6 @@ func paramConditionsMapper(conditions []moduleconfig.Condition) CustomConditionS
 		}
 	}
 }
-func conditionHandler(cond moduleconfig.Condition) CustomConditionSignature {
-	if cond.Action == "KeyMatchCondition" {
-		return KeyMatchCondition(cond.MatchField, cond.WhenValue)
-	} else {
-		flog.Errorf("Unsupported condition")
-		return nil
-	}
-}
 
 func appendToSet(set []string, toAppend []string) []string {
 	for _, appendee := range toAppend {
@@ -0,0 +1,303 @@
+// Package lint finds mistakes in modules without rendering them, by parsing their templates
+// the way `zero create` does and comparing what they reference with the module's zero-module.yml
+package lint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/constants"
+	"github.com/commitdev/zero/internal/generate"
+	"github.com/commitdev/zero/internal/util"
+)
+
+// Severity tells whether an issue is a mistake or only worth a look
+type Severity string
+
+const (
+	// SeverityError makes `zero module lint` exit non-zero
+	SeverityError Severity = "error"
+	// SeverityWarning is for issues that can be intended, eg: parameters only used as env vars by the module's commands
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a mistake found in a module, File is relative to the module's inputDir, partialsDir or is zero-module.yml
+type Issue struct {
+	File     string
+	Line     int
+	Message  string
+	Severity Severity
+}
+
+func (i Issue) String() string {
+	message := i.Message
+	if i.Severity == SeverityWarning {
+		message = "warning: " + message
+	}
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, message)
+	}
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, message)
+}
+
+// builtins are the functions of text/template, they are not part of util.FuncMap
+var builtins = []string{"and", "call", "html", "index", "slice", "js", "len", "not", "or", "print", "printf", "println", "urlquery", "eq", "ge", "gt", "le", "lt", "ne"}
+
+var (
+	undefinedFunctionPattern = regexp.MustCompile(`^template: [^:]*:(\d+): function "([^"]+)" not defined$`)
+	errorLinePattern         = regexp.MustCompile(`^template: [^:]*:(\d+):(?:\d+:)? ?(.*)$`)
+)
+
+// reference is a use of a parameter by a template
+type reference struct {
+	field string
+	line  int
+}
+
+// Module returns the issues of a module:
+// references to parameters that are not declared, declared parameters that are never used,
+// calls to functions that don't exist and conditions on parameters that are not declared.
+// Unused parameters are warnings, they can be used as env vars by the module's commands
+func Module(moduleRoot string, moduleConfig moduleconfig.ModuleConfig) ([]Issue, error) {
+	templates, err := generate.ModuleTemplates(moduleRoot, moduleConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := map[string]moduleconfig.Parameter{}
+	for _, parameter := range moduleConfig.Parameters {
+		declared[parameter.Field] = parameter
+	}
+	used := map[string]bool{}
+	usesAllParams := false
+	issues := []Issue{}
+
+	checkReference := func(file string, ref reference) {
+		used[ref.field] = true
+		parameter, ok := declared[ref.field]
+		switch {
+		case !ok:
+			issues = append(issues, Issue{file, ref.line, fmt.Sprintf(".Params.%s is not a declared parameter", ref.field), SeverityError})
+		case parameter.OmitFromProjectFile:
+			issues = append(issues, Issue{file, ref.line, fmt.Sprintf(".Params.%s is omitted from the project file, it is not available to templates", ref.field), SeverityError})
+		}
+	}
+
+	for _, template := range templates {
+		file := template.Source
+		if template.Partial {
+			file = path.Join(moduleConfig.PartialsDir, template.Source)
+		}
+
+		trees, parseIssues := parseTemplate(file, template.Body, template.LeftDelim, template.RightDelim, template.FrontMatterLines)
+		issues = append(issues, parseIssues...)
+		for _, tree := range trees {
+			refs, all := paramReferences(tree, tree.Root, true, template.FrontMatterLines)
+			usesAllParams = usesAllParams || all
+			for _, ref := range refs {
+				checkReference(file, ref)
+			}
+		}
+
+		// file names can be templates too
+		if !template.Partial && strings.Contains(template.Source, template.LeftDelim) {
+			trees, parseIssues := parseTemplate(file, template.Source, template.LeftDelim, template.RightDelim, 0)
+			for _, issue := range parseIssues {
+				issue.Line = 0
+				issues = append(issues, issue)
+			}
+			for _, tree := range trees {
+				refs, all := paramReferences(tree, tree.Root, true, 0)
+				usesAllParams = usesAllParams || all
+				for _, ref := range refs {
+					ref.line = 0
+					checkReference(file, ref)
+				}
+			}
+		}
+
+		if template.Condition != nil {
+			for _, field := range template.Condition.Fields() {
+				used[field] = true
+				if _, ok := declared[field]; !ok {
+					issues = append(issues, Issue{file, 1, fmt.Sprintf("front matter condition matchField %s is not a declared parameter", field), SeverityError})
+				}
+			}
+		}
+	}
+
+	for i, condition := range moduleConfig.Conditions {
+		for _, field := range condition.Fields() {
+			used[field] = true
+			if _, ok := declared[field]; !ok {
+				issues = append(issues, Issue{constants.ZeroModuleYml, 0, fmt.Sprintf("conditions[%d]: matchField %s is not a declared parameter", i, field), SeverityError})
+			}
+		}
+	}
+	for _, parameter := range moduleConfig.Parameters {
+		for i, condition := range parameter.Conditions {
+			for _, field := range condition.Fields() {
+				used[field] = true
+				if _, ok := declared[field]; !ok {
+					issues = append(issues, Issue{constants.ZeroModuleYml, 0, fmt.Sprintf("parameters.%s.conditions[%d]: matchField %s is not a declared parameter", parameter.Field, i, field), SeverityError})
+				}
+			}
+		}
+		references, err := parameter.References()
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range references {
+			used[field] = true
+		}
+	}
+
+	if !usesAllParams {
+		for _, parameter := range moduleConfig.Parameters {
+			// parameters omitted from the project file are only used while prompting
+			if !used[parameter.Field] && !parameter.OmitFromProjectFile {
+				issues = append(issues, Issue{constants.ZeroModuleYml, 0, fmt.Sprintf("parameters.%s is not used by any template or condition", parameter.Field), SeverityWarning})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// parseTemplate parses a template with the functions available to `zero create`,
+// each undefined function is reported then declared so parsing can go on to find the next
+func parseTemplate(file string, text string, leftDelim string, rightDelim string, lineOffset int) (map[string]*parse.Tree, []Issue) {
+	// parse only checks the names of functions, their values must not be nil
+	funcs := map[string]interface{}{"include": true}
+	for name := range util.FuncMap {
+		funcs[name] = true
+	}
+	for _, name := range builtins {
+		funcs[name] = true
+	}
+
+	issues := []Issue{}
+	for {
+		trees, err := parse.Parse(file, text, leftDelim, rightDelim, funcs)
+		if err == nil {
+			return trees, issues
+		}
+		if match := undefinedFunctionPattern.FindStringSubmatch(err.Error()); match != nil && funcs[match[2]] == nil {
+			issues = append(issues, Issue{file, lineOf(match[1], lineOffset), fmt.Sprintf("function %s is not defined", match[2]), SeverityError})
+			funcs[match[2]] = true
+			continue
+		}
+		if match := errorLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			return nil, append(issues, Issue{file, lineOf(match[1], lineOffset), match[2], SeverityError})
+		}
+		return nil, append(issues, Issue{file, 0, err.Error(), SeverityError})
+	}
+}
+
+// paramReferences returns the parameters referenced with .Params.<field>, $.Params.<field> or index .Params "<field>",
+// and whether .Params is used as a whole. The dot of range and with bodies is not the template's data,
+// only references through $ are returned for them
+func paramReferences(tree *parse.Tree, node parse.Node, dotIsData bool, lineOffset int) ([]reference, bool) {
+	refs := []reference{}
+	all := false
+	walk := func(dotIsData bool, children ...parse.Node) {
+		for _, child := range children {
+			childRefs, childAll := paramReferences(tree, child, dotIsData, lineOffset)
+			refs = append(refs, childRefs...)
+			all = all || childAll
+		}
+	}
+	add := func(children ...parse.Node) {
+		walk(dotIsData, children...)
+	}
+	line := func(n parse.Node) int {
+		location, _ := tree.ErrorContext(n)
+		parts := strings.Split(location, ":")
+		if len(parts) < 2 {
+			return 0
+		}
+		return lineOf(parts[1], lineOffset)
+	}
+	params := func(n parse.Node, ident []string) {
+		if len(ident) == 0 || ident[0] != "Params" {
+			return
+		}
+		if len(ident) == 1 {
+			all = true
+			return
+		}
+		refs = append(refs, reference{ident[1], line(n)})
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return refs, all
+		}
+		for _, child := range n.Nodes {
+			add(child)
+		}
+	case *parse.ActionNode:
+		add(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return refs, all
+		}
+		for _, cmd := range n.Cmds {
+			add(cmd)
+		}
+	case *parse.CommandNode:
+		// index .Params "field"
+		if len(n.Args) == 3 {
+			if function, ok := n.Args[0].(*parse.IdentifierNode); ok && function.Ident == "index" {
+				field, isField := n.Args[1].(*parse.FieldNode)
+				key, isString := n.Args[2].(*parse.StringNode)
+				if dotIsData && isField && isString && len(field.Ident) == 1 && field.Ident[0] == "Params" {
+					refs = append(refs, reference{key.Text, line(n)})
+					return refs, all
+				}
+			}
+		}
+		for _, arg := range n.Args {
+			add(arg)
+		}
+	case *parse.FieldNode:
+		if dotIsData {
+			params(n, n.Ident)
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			params(n, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		add(n.Node)
+	case *parse.IfNode:
+		add(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		add(n.Pipe, n.ElseList)
+		walk(false, n.List)
+	case *parse.WithNode:
+		add(n.Pipe, n.ElseList)
+		walk(false, n.List)
+	case *parse.TemplateNode:
+		add(n.Pipe)
+	}
+	return refs, all
+}
+
+func lineOf(line string, offset int) int {
+	n, _ := strconv.Atoi(line)
+	return n + offset
+}
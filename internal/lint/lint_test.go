@@ -0,0 +1,35 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/commitdev/zero/internal/lint"
+	"github.com/commitdev/zero/internal/module"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModule(t *testing.T) {
+	moduleDir := "../../tests/test_data/lint"
+	mod, err := module.ParseModuleConfig(moduleDir)
+	assert.NoError(t, err)
+
+	issues, err := lint.Module(moduleDir, mod)
+	assert.NoError(t, err)
+
+	messages := []string{}
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		"config.yml:2: .Params.regoin is not a declared parameter",
+		"config.yml:6: function shout is not defined",
+		"config.yml:7: .Params.prompted is omitted from the project file, it is not available to templates",
+		"front_matter.txt:1: front matter condition matchField undeclaredFrontMatter is not a declared parameter",
+		"front_matter.txt:6: function whisper is not defined",
+		"partials/header.txt:1: .Params.header is not a declared parameter",
+		"zero-module.yml: conditions[1]: matchField undeclaredCondition is not a declared parameter",
+		"zero-module.yml: parameters.hostname.conditions[0]: matchField undeclaredPrompt is not a declared parameter",
+		"zero-module.yml: warning: parameters.unused is not used by any template or condition",
+		"{{ .Params.service }}/README.md: .Params.service is not a declared parameter",
+	}, messages)
+}
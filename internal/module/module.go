@@ -5,8 +5,12 @@ import (
 	"encoding/base64"
 	"io"
 	"log"
+	"net/url"
+	"os/exec"
 	"path"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/commitdev/zero/internal/config/moduleconfig"
@@ -53,6 +57,45 @@ func GetSourceDir(source string) string {
 	}
 }
 
+// GetSourceRevision returns the revision of a module's source: the commit checked out when its directory is the root of a git repository,
+// otherwise the ref of the source url, eg: `github.com/commitdev/zero-aws-eks-stack?ref=v0.1.0`, or "" when it is unknown.
+// The commit of a repository the directory is nested in, eg: the project's own, is not the module's
+func GetSourceRevision(source string) string {
+	if revision, ok := gitRevision(GetSourceDir(source)); ok {
+		return revision
+	}
+	if sourceURL, err := url.Parse(source); err == nil {
+		return sourceURL.Query().Get("ref")
+	}
+	return ""
+}
+
+// gitRevision returns the commit checked out in dir when it is the root of a git repository
+func gitRevision(dir string) (string, bool) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		return "", false
+	}
+	// git resolves symlinks in the path of the repository
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+	if filepath.Clean(lines[0]) != absDir {
+		return "", false
+	}
+	return lines[1], true
+}
+
 // IsLocal uses the go-getter FileDetector to check if source is a file
 func IsLocal(source string) bool {
 	pwd := util.GetCwd()
@@ -3,6 +3,10 @@ package module_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/commitdev/zero/internal/config/moduleconfig"
@@ -29,6 +33,28 @@ func TestGetSourceDir(t *testing.T) {
 	}
 }
 
+func TestGetSourceRevision(t *testing.T) {
+	assert.Equal(t, "v0.1.0", module.GetSourceRevision("github.com/commitdev/my-repo?ref=v0.1.0"), "remote sources not fetched yet should use their ref")
+	assert.Equal(t, "", module.GetSourceRevision("github.com/commitdev/my-repo"))
+
+	dir, err := ioutil.TempDir("", "revision")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.Equal(t, "", module.GetSourceRevision(dir), "local sources outside of a repository have no revision")
+	assert.Equal(t, "", module.GetSourceRevision("../../tests/test_data/modules/ci"), "the revision of an enclosing repository is not the module's")
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		assert.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "module")
+	assert.Equal(t, git("rev-parse", "HEAD"), module.GetSourceRevision(dir), "the commit checked out in the module's repository")
+}
+
 func TestParseModuleConfig(t *testing.T) {
 	testModuleSource := "../../tests/test_data/modules/ci"
 	var mod moduleconfig.ModuleConfig
@@ -74,6 +100,19 @@ func TestParseModuleConfig(t *testing.T) {
 		assert.Equal(t, "yes", param.Conditions[0].WhenValue)
 	})
 
+	t.Run("Parsing secret parameters", func(t *testing.T) {
+		param, err := findParameter(mod.Parameters, "secretAccessKey")
+		if err != nil {
+			panic(err)
+		}
+		assert.Equal(t, true, param.Secret)
+		param, err = findParameter(mod.Parameters, "accessKeyId")
+		if err != nil {
+			panic(err)
+		}
+		assert.Equal(t, false, param.Secret, "Secret should default to false")
+	})
+
 	t.Run("parsing envVarName from module config", func(t *testing.T) {
 		param, err := findParameter(mod.Parameters, "accessKeyId")
 		if err != nil {
@@ -164,6 +203,40 @@ func TestModuleWithNoVersionConstraint(t *testing.T) {
 	})
 }
 
+func TestModuleWithNewerAPIVersion(t *testing.T) {
+	testModuleSource := "../../tests/test_data/modules/future-version"
+
+	_, err := module.ParseModuleConfig(testModuleSource)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "module file uses apiVersion v99 but this version of Zero only supports up to "+moduleconfig.CurrentAPIVersion)
+}
+
+func TestInvalidModule(t *testing.T) {
+	_, err := module.ParseModuleConfig("../../tests/test_data/modules/invalid")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "author: is required")
+	// unknown fields are only warned about
+	assert.NotContains(t, err.Error(), `unknown field "matchFeild"`)
+}
+
+func TestModuleWithInvalidCondition(t *testing.T) {
+	_, err := module.ParseModuleConfig("../../tests/test_data/modules/invalid-condition")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `parameters.databaseName.conditions[0]: unsupported operator "startsWith"`)
+}
+
+func TestModuleWithUnknownCredentials(t *testing.T) {
+	_, err := module.ParseModuleConfig("../../tests/test_data/modules/unknown-credentials")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `requiredCredentials: unknown credential vendor "datadog"`)
+}
+
+func TestModuleAPIVersionDefaultsToCurrent(t *testing.T) {
+	mod, err := module.ParseModuleConfig("../../tests/test_data/modules/ci")
+	assert.NoError(t, err)
+	assert.Equal(t, moduleconfig.CurrentAPIVersion, mod.APIVersion, "unversioned modules are migrated when loaded")
+}
+
 func findParameter(params []moduleconfig.Parameter, field string) (moduleconfig.Parameter, error) {
 	for _, v := range params {
 		if v.Field == field {
@@ -0,0 +1,198 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/commitdev/zero/internal/config/moduleconfig"
+	"github.com/commitdev/zero/internal/constants"
+	goVerson "github.com/hashicorp/go-version"
+)
+
+// ModuleInfo describes the module to scaffold
+type ModuleInfo struct {
+	Name        string
+	Description string
+	Author      string
+	// ZeroVersion is the version constraint of the module, see ZeroVersionConstraint
+	ZeroVersion string
+}
+
+// scaffoldFile is a file of the skeleton module, its path and content are templates rendered with ModuleInfo
+type scaffoldFile struct {
+	path    string
+	content string
+	mode    os.FileMode
+}
+
+// the files are rendered with [[ ]] so they can contain both zero's parameter templates and the module's delimiters
+var leftDelim, rightDelim = "[[", "]]"
+
+var files = []scaffoldFile{
+	{path: constants.ZeroModuleYml, content: moduleConfigTemplate, mode: 0644},
+	{path: "Makefile", content: makefileTemplate, mode: 0644},
+	{path: "templates/README.md", content: readmeTemplate, mode: 0644},
+	{path: "templates/config/app.yml", content: appConfigTemplate, mode: 0644},
+	{path: "templates/monitoring/alerts.yml", content: alertsTemplate, mode: 0644},
+}
+
+// ZeroVersionConstraint returns a constraint compatible with the given version of zero up to its next major version,
+// eg: `>= 0.1.0, < 1.0.0`. Unreleased versions (eg: SNAPSHOT) are not a valid constraint so none is returned
+func ZeroVersionConstraint(zeroVersion string) string {
+	v, err := goVerson.NewVersion(zeroVersion)
+	if err != nil {
+		return ""
+	}
+	segments := v.Segments()
+	return fmt.Sprintf(">= %d.%d.0, < %d.0.0", segments[0], segments[1], segments[0]+1)
+}
+
+// Scaffold writes a skeleton module to dir, it fails if dir already exists so nothing is overwritten
+func Scaffold(dir string, info ModuleInfo) error {
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	funcs := template.FuncMap{"quote": strconv.Quote}
+	for _, file := range files {
+		tmpl, err := template.New(file.path).Delims(leftDelim, rightDelim).Funcs(funcs).Parse(file.content)
+		if err != nil {
+			return err
+		}
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, info); err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(dir, filepath.FromSlash(file.path))
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filePath, content.Bytes(), file.mode); err != nil {
+			return err
+		}
+	}
+
+	// make sure the skeleton is a valid module
+	if _, err := moduleconfig.LoadModuleConfig(filepath.Join(dir, constants.ZeroModuleYml)); err != nil {
+		return fmt.Errorf("the generated module is invalid: %v", err)
+	}
+	return nil
+}
+
+const moduleConfigTemplate = `apiVersion: v1
+name: [[ quote .Name ]]
+description: [[ quote .Description ]]
+author: [[ quote .Author ]]
+icon: ""
+thumbnail: ""
+[[- if .ZeroVersion ]]
+zeroVersion: [[ quote .ZeroVersion ]]
+[[- else ]]
+# zeroVersion: ">= 1.0.0, < 2.0.0"
+[[- end ]]
+
+# Commands run by zero apply from the module's directory, see the Makefile
+commands:
+  check: make check
+  apply: make apply
+  summary: make summary
+
+# Credentials prompted during zero init, available as env vars during zero apply
+requiredCredentials:
+  - github
+
+# Templating of the module's files during zero create
+template:
+  # only render files ending in .tmpl when true, other files are copied as is
+  strictMode: false
+  delimiters:
+    - "<%"
+    - "%>"
+  inputDir: templates
+  outputDir: [[ quote .Name ]]
+
+# Parameters prompted during zero init, available in templates as <% .Params.field %>
+parameters:
+  - field: region
+    label: AWS region to deploy to
+    options:
+      us-east-1: us-east-1 - N. Virginia
+      us-west-2: us-west-2 - Oregon
+  - field: domain
+    label: "Domain name of the application (eg: example.com)"
+    fieldValidation:
+      type: domain
+  - field: apiHost
+    label: Hostname of the API
+    # defaults can refer to the answers of other parameters and the project name
+    default: "api.{{ .domain }}"
+  - field: replicas
+    label: Number of replicas
+    type: int
+    default: "2"
+    fieldValidation:
+      type: range
+      value: "1..10"
+  - field: enableMonitoring
+    label: Enable monitoring?
+    type: bool
+    default: "yes"
+  - field: alertEmail
+    label: Email address to send alerts to
+    fieldValidation:
+      type: email
+    conditions:
+      - action: KeyMatchCondition
+        matchField: enableMonitoring
+        whenValue: "true"
+
+# Conditions applied to the module's files during zero create
+conditions:
+  - action: ignoreFile
+    matchField: enableMonitoring
+    whenValue: "false"
+    data:
+      - monitoring/
+`
+
+const makefileTemplate = `# Targets run by zero apply, parameters are available as env vars
+
+check:
+	@command -v git >/dev/null || (echo "git is required" && exit 1)
+	@test -n "$(GITHUB_ACCESS_TOKEN)" || (echo "GITHUB_ACCESS_TOKEN is required" && exit 1)
+
+apply:
+	@echo "Applying [[ .Name ]] of $(PROJECT_NAME) to $(ENVIRONMENT) in $(region)"
+
+summary:
+	@echo "[[ .Name ]] is available at https://$(apiHost)"
+
+.PHONY: check apply summary
+`
+
+const readmeTemplate = `# <% .Name %>
+
+[[ .Description ]]
+
+The API is served from https://<% .Params.apiHost %> with <% .Params.replicas %> replicas in <% .Params.region %>.
+`
+
+const appConfigTemplate = `name: <% .Name %>
+host: <% .Params.apiHost %>
+replicas: <% .Params.replicas %>
+<%- if .Params.enableMonitoring %>
+alertEmail: <% .Params.alertEmail %>
+<%- end %>
+`
+
+const alertsTemplate = `# Only generated when monitoring is enabled, see the conditions of zero-module.yml
+alerts:
+  - name: <% .Name %>-down
+    notify: <% .Params.alertEmail %>
+`
@@ -0,0 +1,54 @@
+package scaffold_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/commitdev/zero/internal/module"
+	"github.com/commitdev/zero/internal/scaffold"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaffold(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "scaffold")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "my-module")
+	info := scaffold.ModuleInfo{
+		Name:        "my-module",
+		Description: `A module with "quotes"`,
+		Author:      "Commit",
+		ZeroVersion: scaffold.ZeroVersionConstraint("0.1.2"),
+	}
+
+	t.Run("Should create a valid module", func(t *testing.T) {
+		err := scaffold.Scaffold(dir, info)
+		assert.NoError(t, err)
+
+		mod, err := module.ParseModuleConfig(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, "my-module", mod.Name)
+		assert.Equal(t, `A module with "quotes"`, mod.Description)
+		assert.Equal(t, ">= 0.1.0, < 1.0.0", mod.ZeroVersion.String())
+		assert.Equal(t, []string{"<%", "%>"}, mod.Delimiters)
+		assert.Equal(t, "make check", mod.Commands.Check)
+
+		assert.FileExists(t, filepath.Join(dir, "Makefile"))
+		readme, err := ioutil.ReadFile(filepath.Join(dir, "templates/README.md"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(readme), "<% .Params.apiHost %>")
+	})
+
+	t.Run("Should not overwrite an existing directory", func(t *testing.T) {
+		err := scaffold.Scaffold(dir, info)
+		assert.EqualError(t, err, dir+" already exists")
+	})
+}
+
+func TestZeroVersionConstraint(t *testing.T) {
+	assert.Equal(t, ">= 2.3.0, < 3.0.0", scaffold.ZeroVersionConstraint("v2.3.4"))
+	assert.Equal(t, "", scaffold.ZeroVersionConstraint("SNAPSHOT"))
+}
@@ -0,0 +1,165 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/iancoleman/strcase"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// @TODO how can we make these type of helpers extensible?
+var FuncMap = template.FuncMap{
+	"Title":             strings.Title,
+	"ToLower":           strings.ToLower,
+	"CleanGoIdentifier": CleanGoIdentifier,
+	"GenerateUUID":      uuid.New,
+
+	// case conversions
+	"camelcase":  func(s interface{}) string { return strcase.ToLowerCamel(toString(s)) },
+	"pascalcase": func(s interface{}) string { return strcase.ToCamel(toString(s)) },
+	"snakecase":  func(s interface{}) string { return strcase.ToSnake(toString(s)) },
+	"kebabcase":  func(s interface{}) string { return strcase.ToKebab(toString(s)) },
+	"upper":      func(s interface{}) string { return strings.ToUpper(toString(s)) },
+	"lower":      func(s interface{}) string { return strings.ToLower(toString(s)) },
+
+	// defaults and conditionals
+	"default":  defaultValue,
+	"required": required,
+	"ternary":  ternary,
+
+	// strings
+	"trim":    func(s interface{}) string { return strings.TrimSpace(toString(s)) },
+	"replace": func(old string, new string, s interface{}) string { return strings.Replace(toString(s), old, new, -1) },
+	"split":   func(sep string, s interface{}) []string { return strings.Split(toString(s), sep) },
+	"join":    join,
+	"indent":  indent,
+	"nindent": func(spaces int, s interface{}) string { return "\n" + indent(spaces, s) },
+
+	// encodings
+	"toYaml":    toYaml,
+	"toJson":    toJSON,
+	"b64enc":    func(s interface{}) string { return base64.StdEncoding.EncodeToString([]byte(toString(s))) },
+	"sha256sum": sha256sum,
+
+	"randAlphaNum": randAlphaNum,
+	"env":          os.Getenv,
+}
+
+// toString formats template values, nil is an empty string
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// isEmpty returns whether a value is nil, false, zero or has no elements
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// defaultValue returns the value unless it is empty, eg: {{ .Params.region | default "us-west-2" }}
+func defaultValue(defaultValue interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return defaultValue
+	}
+	return value[0]
+}
+
+// required fails the rendering with the message when the value is empty, eg: {{ required "domain is required" .Params.domain }}
+func required(message string, value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		return nil, errors.New(message)
+	}
+	return value, nil
+}
+
+// ternary returns the first value when the condition is true, eg: {{ ternary "https" "http" .Params.tls }}
+func ternary(trueValue interface{}, falseValue interface{}, condition bool) interface{} {
+	if condition {
+		return trueValue
+	}
+	return falseValue
+}
+
+// join joins the items of a list, eg: {{ join "," .Params.subdomains }}
+func join(sep string, list interface{}) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return toString(list)
+	}
+	items := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items[i] = toString(v.Index(i).Interface())
+	}
+	return strings.Join(items, sep)
+}
+
+// indent prefixes every line with the number of spaces
+func indent(spaces int, s interface{}) string {
+	padding := strings.Repeat(" ", spaces)
+	return padding + strings.Replace(toString(s), "\n", "\n"+padding, -1)
+}
+
+func toYaml(value interface{}) (string, error) {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func toJSON(value interface{}) (string, error) {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func sha256sum(s interface{}) string {
+	sum := sha256.Sum256([]byte(toString(s)))
+	return hex.EncodeToString(sum[:])
+}
+
+const alphaNum = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randAlphaNum returns a random string of letters and digits, eg: to generate passwords
+func randAlphaNum(length int) (string, error) {
+	out := make([]byte, length)
+	for i := range out {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphaNum))))
+		if err != nil {
+			return "", err
+		}
+		out[i] = alphaNum[n.Int64()]
+	}
+	return string(out), nil
+}
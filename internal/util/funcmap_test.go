@@ -0,0 +1,70 @@
+package util_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"text/template"
+
+	"github.com/commitdev/zero/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func render(t *testing.T, text string, data interface{}) (string, error) {
+	tmpl, err := template.New("test").Funcs(util.FuncMap).Parse(text)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	return out.String(), err
+}
+
+func TestFuncMap(t *testing.T) {
+	os.Setenv("ZERO_FUNCMAP_TEST", "from-env")
+	defer os.Unsetenv("ZERO_FUNCMAP_TEST")
+
+	data := map[string]interface{}{
+		"name":       "my api-server",
+		"empty":      "",
+		"tls":        true,
+		"replicas":   3,
+		"subdomains": []interface{}{"api", "www"},
+		"labels":     map[string]interface{}{"team": "platform"},
+	}
+
+	cases := []struct {
+		template string
+		expected string
+	}{
+		{`{{ camelcase .name }}`, "myApiServer"},
+		{`{{ pascalcase .name }}`, "MyApiServer"},
+		{`{{ snakecase .name }}`, "my_api_server"},
+		{`{{ kebabcase .name }}`, "my-api-server"},
+		{`{{ upper .name }}`, "MY API-SERVER"},
+		{`{{ .empty | default "fallback" }}`, "fallback"},
+		{`{{ .missing | default "fallback" }}`, "fallback"},
+		{`{{ .replicas | default 1 }}`, "3"},
+		{`{{ required "name is required" .name }}`, "my api-server"},
+		{`{{ ternary "https" "http" .tls }}`, "https"},
+		{`{{ trim "  padded  " }}`, "padded"},
+		{`{{ replace "-" "_" .name }}`, "my api_server"},
+		{`{{ join "," (split " " .name) }}`, "my,api-server"},
+		{`{{ join "," .subdomains }}`, "api,www"},
+		{`{{ "a\nb" | indent 2 }}`, "  a\n  b"},
+		{`key:{{ "a: 1" | nindent 2 }}`, "key:\n  a: 1"},
+		{`{{ toYaml .labels }}`, "team: platform"},
+		{`{{ toJson .subdomains }}`, `["api","www"]`},
+		{`{{ b64enc "zero" }}`, "emVybw=="},
+		{`{{ sha256sum "zero" }}`, "f9194e73f9e9459e3450ea10a179cdf77aafa695beecd3b9344a98d111622243"},
+		{`{{ len (randAlphaNum 16) }}`, "16"},
+		{`{{ env "ZERO_FUNCMAP_TEST" }}`, "from-env"},
+	}
+	for _, c := range cases {
+		out, err := render(t, c.template, data)
+		assert.NoError(t, err, c.template)
+		assert.Equal(t, c.expected, out, c.template)
+	}
+
+	_, err := render(t, `{{ required "domain is required" .empty }}`, data)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "domain is required")
+}
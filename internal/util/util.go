@@ -17,9 +17,6 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
-	"text/template"
-
-	"github.com/google/uuid"
 )
 
 func CreateDirIfDoesNotExist(path string) error {
@@ -34,14 +31,6 @@ func CleanGoIdentifier(identifier string) string {
 	return strings.ReplaceAll(identifier, "-", "")
 }
 
-// @TODO how can we make these type of helpers extensible?
-var FuncMap = template.FuncMap{
-	"Title":             strings.Title,
-	"ToLower":           strings.ToLower,
-	"CleanGoIdentifier": CleanGoIdentifier,
-	"GenerateUUID":      uuid.New,
-}
-
 func GetCwd() string {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultEditor = "vi"
+
+// Edit decrypts the file at filePath into a temporary file, opens it with the user's $EDITOR
+// then re-encrypts the result with the same key. If the file does not exist yet, the editor
+// starts with initialContent and a new key is used. validate is run on the edited content before saving it,
+// when it fails the editor is opened again with the error at the top of the file. If the file is then saved
+// without changes the edit is cancelled and the temporary file is kept so the edits are not lost.
+func Edit(filePath string, initialContent []byte, validate func([]byte) error) error {
+	plaintext, key, err := ReadFile(filePath)
+	if os.IsNotExist(err) {
+		plaintext = initialContent
+		key, err = NewKey()
+	}
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile("", "zero-secrets-*.yml")
+	if err != nil {
+		return err
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			os.Remove(tmpFile.Name())
+		}
+	}()
+	_, err = tmpFile.Write(plaintext)
+	tmpFile.Close()
+	if err != nil {
+		return err
+	}
+
+	var header, previous []byte
+	for {
+		if err := runEditor(tmpFile.Name()); err != nil {
+			return err
+		}
+		edited, err := ioutil.ReadFile(tmpFile.Name())
+		if err != nil {
+			return err
+		}
+		edited = bytes.TrimPrefix(edited, header)
+		var validationErr error
+		if validate != nil {
+			validationErr = validate(edited)
+		}
+		if validationErr == nil {
+			return WriteFile(filePath, edited, key)
+		}
+		if previous != nil && bytes.Equal(edited, previous) {
+			keep = true
+			return fmt.Errorf("%v\nthe edit was cancelled, the edited secrets are kept in %s", validationErr, tmpFile.Name())
+		}
+
+		header = editErrorHeader(validationErr)
+		previous = edited
+		if err := ioutil.WriteFile(tmpFile.Name(), append(header, edited...), 0600); err != nil {
+			return err
+		}
+	}
+}
+
+// runEditor opens the file with the user's $EDITOR
+func runEditor(fileName string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+	}
+	args := append(strings.Fields(editor), fileName)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// editErrorHeader is the comment shown at the top of the file when the edited secrets are invalid
+func editErrorHeader(err error) []byte {
+	lines := []string{"# The secrets could not be saved, fix the error below or save the file unchanged to cancel:"}
+	for _, line := range strings.Split(err.Error(), "\n") {
+		lines = append(lines, "# "+line)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n#\n")
+}
+
+// Rotate re-encrypts the file at filePath with newKey
+func Rotate(filePath string, newKey Key) error {
+	plaintext, _, err := ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return WriteFile(filePath, plaintext, newKey)
+}
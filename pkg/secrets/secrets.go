@@ -0,0 +1,265 @@
+// Package secrets encrypts and decrypts files holding sensitive values.
+//
+// A secrets file is a small yaml envelope describing how the content was
+// encrypted, the content itself is sealed with AES-256-GCM.
+// The key is either a random key stored under ~/.zero/keys or derived from
+// a passphrase supplied through the ZERO_SECRETS_PASSPHRASE env var.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/commitdev/zero/internal/constants"
+	"golang.org/x/crypto/scrypt"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PassphraseEnvVariable is the env var holding the passphrase for passphrase encrypted files
+const PassphraseEnvVariable = "ZERO_SECRETS_PASSPHRASE"
+
+const (
+	formatVersion = 1
+	cipherName    = "aes-256-gcm"
+	kdfKeyFile    = "keyfile"
+	kdfScrypt     = "scrypt"
+	keySize       = 32
+	header        = "# Encrypted by zero, use `zero secrets edit` to make changes\n"
+)
+
+// KeyDir is the directory the key files are stored in, defaults to ~/.zero/keys
+var KeyDir = ""
+
+// Key is the key used to encrypt a secrets file, along with how to obtain it again for decryption
+type Key struct {
+	kdf    string
+	id     string
+	salt   []byte
+	secret []byte
+}
+
+// envelope is the on-disk representation of an encrypted file
+type envelope struct {
+	Version int    `yaml:"version"`
+	Cipher  string `yaml:"cipher"`
+	KDF     string `yaml:"kdf"`
+	KeyID   string `yaml:"keyId,omitempty"`
+	Salt    string `yaml:"salt,omitempty"`
+	Nonce   string `yaml:"nonce"`
+	Data    string `yaml:"data"`
+}
+
+// NewKey returns a key for encrypting a new file. If a passphrase is set
+// in the environment it is used, otherwise a random key is generated and stored in KeyDir.
+func NewKey() (Key, error) {
+	if passphrase := os.Getenv(PassphraseEnvVariable); passphrase != "" {
+		return NewPassphraseKey(passphrase)
+	}
+	return NewKeyFileKey()
+}
+
+// NewKeyFileKey generates a random key and stores it in KeyDir
+func NewKeyFileKey() (Key, error) {
+	id, err := randomBytes(8)
+	if err != nil {
+		return Key{}, err
+	}
+	secret, err := randomBytes(keySize)
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{kdf: kdfKeyFile, id: hex.EncodeToString(id), secret: secret}
+
+	keyPath, err := keyFilePath(key.id)
+	if err != nil {
+		return Key{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return Key{}, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(secret) + "\n"
+	if err := ioutil.WriteFile(keyPath, []byte(encoded), 0600); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// NewPassphraseKey derives a key from the passphrase with a random salt
+func NewPassphraseKey(passphrase string) (Key, error) {
+	salt, err := randomBytes(16)
+	if err != nil {
+		return Key{}, err
+	}
+	return passphraseKey(passphrase, salt)
+}
+
+// Description returns a human readable description of where the key comes from
+func (k Key) Description() string {
+	if k.kdf == kdfScrypt {
+		return "passphrase"
+	}
+	keyPath, _ := keyFilePath(k.id)
+	return fmt.Sprintf("key file %s", keyPath)
+}
+
+// ReadFile decrypts the file at filePath, it returns the key used so the content can be re-encrypted with it
+func ReadFile(filePath string) ([]byte, Key, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, Key{}, err
+	}
+	plaintext, key, err := Decrypt(data)
+	if err != nil {
+		return nil, Key{}, fmt.Errorf("unable to decrypt %s: %v", filePath, err)
+	}
+	return plaintext, key, nil
+}
+
+// WriteFile encrypts plaintext with key and writes it to filePath
+func WriteFile(filePath string, plaintext []byte, key Key) error {
+	data, err := Encrypt(plaintext, key)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+// Encrypt seals plaintext with key and returns the encoded envelope
+func Encrypt(plaintext []byte, key Key) ([]byte, error) {
+	gcm, err := newGCM(key.secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Version: formatVersion,
+		Cipher:  cipherName,
+		KDF:     key.kdf,
+		KeyID:   key.id,
+		Nonce:   base64.StdEncoding.EncodeToString(nonce),
+		Data:    base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}
+	if key.kdf == kdfScrypt {
+		env.Salt = base64.StdEncoding.EncodeToString(key.salt)
+	}
+
+	out, err := yaml.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(header), out...), nil
+}
+
+// Decrypt opens an encoded envelope, it returns the plaintext and the key that was used
+func Decrypt(data []byte) ([]byte, Key, error) {
+	env := envelope{}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, Key{}, err
+	}
+	if env.Version > formatVersion || env.Cipher != cipherName {
+		return nil, Key{}, fmt.Errorf("unsupported secrets format (version %d, cipher %q)", env.Version, env.Cipher)
+	}
+
+	key, err := loadKey(env)
+	if err != nil {
+		return nil, Key{}, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, Key{}, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, Key{}, err
+	}
+
+	gcm, err := newGCM(key.secret)
+	if err != nil {
+		return nil, Key{}, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, Key{}, errors.New("wrong key or passphrase, or the file was tampered with")
+	}
+	return plaintext, key, nil
+}
+
+func loadKey(env envelope) (Key, error) {
+	switch env.KDF {
+	case kdfKeyFile:
+		keyPath, err := keyFilePath(env.KeyID)
+		if err != nil {
+			return Key{}, err
+		}
+		encoded, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s not found, it is required to decrypt the secrets: %v", env.KeyID, err)
+		}
+		secret, err := base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid key file %s: %v", keyPath, err)
+		}
+		return Key{kdf: kdfKeyFile, id: env.KeyID, secret: secret}, nil
+
+	case kdfScrypt:
+		passphrase := os.Getenv(PassphraseEnvVariable)
+		if passphrase == "" {
+			return Key{}, fmt.Errorf("secrets are encrypted with a passphrase, set it in %s", PassphraseEnvVariable)
+		}
+		salt, err := base64.StdEncoding.DecodeString(env.Salt)
+		if err != nil {
+			return Key{}, err
+		}
+		return passphraseKey(passphrase, salt)
+	}
+	return Key{}, fmt.Errorf("unsupported key derivation %q", env.KDF)
+}
+
+func passphraseKey(passphrase string, salt []byte) (Key, error) {
+	secret, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{kdf: kdfScrypt, salt: salt, secret: secret}, nil
+}
+
+func keyFilePath(id string) (string, error) {
+	dir := KeyDir
+	if dir == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(usr.HomeDir, constants.ZeroHomeDirectory, "keys")
+	}
+	return filepath.Join(dir, id+".key"), nil
+}
+
+func newGCM(secret []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
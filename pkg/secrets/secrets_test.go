@@ -0,0 +1,143 @@
+package secrets_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/commitdev/zero/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupKeyDir(t *testing.T) (func(), string) {
+	dir, err := ioutil.TempDir("", "zero-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalKeyDir := secrets.KeyDir
+	secrets.KeyDir = filepath.Join(dir, "keys")
+	return func() {
+		secrets.KeyDir = originalKeyDir
+		os.RemoveAll(dir)
+	}, dir
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	teardown, dir := setupKeyDir(t)
+	defer teardown()
+	secretsPath := filepath.Join(dir, "zero-project.secrets")
+	plaintext := []byte("modules:\n  backend:\n    token: abc\n")
+
+	t.Run("Should round trip with a key file", func(t *testing.T) {
+		key, err := secrets.NewKeyFileKey()
+		assert.NoError(t, err)
+		assert.NoError(t, secrets.WriteFile(secretsPath, plaintext, key))
+
+		content, err := ioutil.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "token")
+
+		decrypted, _, err := secrets.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("Should round trip with a passphrase", func(t *testing.T) {
+		key, err := secrets.NewPassphraseKey("correct horse")
+		assert.NoError(t, err)
+		assert.NoError(t, secrets.WriteFile(secretsPath, plaintext, key))
+
+		os.Setenv(secrets.PassphraseEnvVariable, "correct horse")
+		decrypted, _, err := secrets.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		os.Setenv(secrets.PassphraseEnvVariable, "wrong horse")
+		_, _, err = secrets.ReadFile(secretsPath)
+		assert.Error(t, err)
+
+		os.Unsetenv(secrets.PassphraseEnvVariable)
+		_, _, err = secrets.ReadFile(secretsPath)
+		assert.Contains(t, err.Error(), secrets.PassphraseEnvVariable)
+	})
+
+	t.Run("Should rotate to a new key", func(t *testing.T) {
+		key, err := secrets.NewKeyFileKey()
+		assert.NoError(t, err)
+		assert.NoError(t, secrets.WriteFile(secretsPath, plaintext, key))
+
+		newKey, err := secrets.NewKeyFileKey()
+		assert.NoError(t, err)
+		assert.NoError(t, secrets.Rotate(secretsPath, newKey))
+
+		decrypted, usedKey, err := secrets.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+		assert.Equal(t, newKey.Description(), usedKey.Description())
+	})
+}
+
+// setupEditor sets $EDITOR to a script writing each of the contents in turn, one per time the editor is opened,
+// the files it was opened with are copied to <dir>/opened-<n>
+func setupEditor(t *testing.T, dir string, contents ...string) func() {
+	script := "n=$(ls " + dir + " | grep -c opened-)\ncp \"$1\" " + dir + "/opened-$n\n"
+	for i, content := range contents {
+		script += fmt.Sprintf("[ $n -eq %d ] && printf '%%s' '%s' > \"$1\"\n", i, content)
+	}
+	scriptPath := filepath.Join(dir, "editor.sh")
+	assert.NoError(t, ioutil.WriteFile(scriptPath, []byte(script+"exit 0\n"), 0755))
+	originalEditor := os.Getenv("EDITOR")
+	os.Setenv("EDITOR", "sh "+scriptPath)
+	return func() {
+		os.Setenv("EDITOR", originalEditor)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	validate := func(content []byte) error {
+		if strings.Contains(string(content), "invalid") {
+			return errors.New("invalid secrets")
+		}
+		return nil
+	}
+
+	t.Run("Invalid edits re-open the editor with the error", func(t *testing.T) {
+		teardown, dir := setupKeyDir(t)
+		defer teardown()
+		defer setupEditor(t, dir, "token: invalid", "token: abc")()
+		secretsPath := filepath.Join(dir, "zero-project.secrets")
+
+		assert.NoError(t, secrets.Edit(secretsPath, []byte("token:"), validate))
+		plaintext, _, err := secrets.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "token: abc", string(plaintext))
+
+		reopened, err := ioutil.ReadFile(filepath.Join(dir, "opened-1"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(reopened), "# invalid secrets\n", "the error should be shown")
+		assert.True(t, strings.HasSuffix(string(reopened), "token: invalid"), "the edits should be kept")
+	})
+
+	t.Run("Saving invalid edits unchanged cancels the edit and keeps them", func(t *testing.T) {
+		teardown, dir := setupKeyDir(t)
+		defer teardown()
+		defer setupEditor(t, dir, "token: invalid")()
+		secretsPath := filepath.Join(dir, "zero-project.secrets")
+
+		err := secrets.Edit(secretsPath, []byte("token:"), validate)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "the edit was cancelled, the edited secrets are kept in ")
+		kept := strings.TrimSpace(err.Error()[strings.LastIndex(err.Error(), " "):])
+		defer os.Remove(kept)
+		content, err := ioutil.ReadFile(kept)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "token: invalid")
+
+		_, err = os.Stat(secretsPath)
+		assert.True(t, os.IsNotExist(err), "invalid secrets should not be saved")
+	})
+}